@@ -2,7 +2,7 @@ package main
 
 import (
 	"encoding/json"
-	"github.com/ToQoz/go-formspec"
+	"github.com/ToQoz/go-formspec/v2"
 	"log"
 	"net/http"
 )
@@ -65,6 +65,16 @@ func main() {
 		w.Write([]byte(`{"message": "ok"}`))
 	})
 
+	// *** Validate form by middleware ***
+	// Invalid requests are responded with 422 in JSON, problem details or plain text by Accept header.
+	http.Handle("/signup", formspec.Handler(sampleFormSpec, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vr := formspec.ResultFrom(r.Context())
+		j, _ := json.Marshal(map[string]string{"message": "hello " + vr.Value("nick")})
+
+		w.Header().Set("Content-Type", "application/json; charset=utf8")
+		w.Write(j)
+	})))
+
 	// *** Validate model ***
 	// Use *formspec.Result for model.Validate return value.
 	// This way is good for unifying validation error expression in app.
@@ -0,0 +1,48 @@
+package formspec
+
+// BailField makes the fields stop at their first failing rule. Use Formspec.Bail to do it for all fields.
+//
+//	s.Rule("name", formspec.RuleRequired())
+//	s.Rule("name", formspec.RuleMinLen(3)) // not called when name is blank
+//	s.BailField("name")
+func (f *Formspec) BailField(fields ...string) *Formspec {
+	if f.bailFields == nil {
+		f.bailFields = map[string]bool{}
+	}
+
+	for _, field := range fields {
+		f.bailFields[field] = true
+	}
+
+	return f
+}
+
+// bails returns true if the field stops at its first failing rule.
+func (f *Formspec) bails(field string) bool {
+	return f.Bail || f.bailFields[field]
+}
+
+// chains groups indexes of rules that must be called one by one, in declared order.
+// Rules of a field that bails are a chain, and other rules are chains by themselves.
+func (f *Formspec) chains() [][]int {
+	var chains [][]int
+
+	fieldChain := map[string]int{}
+
+	for i, rule := range f.Rules {
+		if !f.bails(rule.Field) {
+			chains = append(chains, []int{i})
+			continue
+		}
+
+		if c, ok := fieldChain[rule.Field]; ok {
+			chains[c] = append(chains[c], i)
+			continue
+		}
+
+		fieldChain[rule.Field] = len(chains)
+		chains = append(chains, []int{i})
+	}
+
+	return chains
+}
@@ -0,0 +1,132 @@
+package formspec
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// -----------------------------------------------------------------------------
+// Test formspec.Bail
+// -----------------------------------------------------------------------------
+
+func newBailTestSpec(called *[]string) *Formspec {
+	var mu sync.Mutex
+
+	rule := func(name string, valid bool) RuleFunc {
+		return func(value string, f Form) error {
+			mu.Lock()
+			*called = append(*called, name)
+			mu.Unlock()
+
+			if valid {
+				return nil
+			}
+
+			return errors.New("is invalid by " + name + ".")
+		}
+	}
+
+	s := New()
+	s.Rule("name", rule("name1", false))
+	s.Rule("name", rule("name2", false))
+	s.Rule("age", rule("age1", true))
+	s.Rule("age", rule("age2", false))
+	s.Rule("age", rule("age3", false))
+	return s
+}
+
+func testBailMessages(r *Result) []string {
+	var messages []string
+
+	for _, err := range r.Errors {
+		messages = append(messages, err.Message)
+	}
+
+	return messages
+}
+
+func TestBail(t *testing.T) {
+	examples := []struct {
+		setup    func(s *Formspec)
+		called   []string
+		expected []string
+	}{
+		{
+			func(s *Formspec) {},
+			[]string{"name1", "name2", "age1", "age2", "age3"},
+			[]string{"name is invalid by name1.", "name is invalid by name2.", "age is invalid by age2.", "age is invalid by age3."},
+		},
+		{
+			func(s *Formspec) { s.BailField("age") },
+			[]string{"name1", "name2", "age1", "age2"},
+			[]string{"name is invalid by name1.", "name is invalid by name2.", "age is invalid by age2."},
+		},
+		{
+			func(s *Formspec) { s.Bail = true },
+			[]string{"name1", "age1", "age2"},
+			[]string{"name is invalid by name1.", "age is invalid by age2."},
+		},
+		{
+			func(s *Formspec) { s.FailFast = true },
+			[]string{"name1"},
+			[]string{"name is invalid by name1."},
+		},
+	}
+
+	for _, example := range examples {
+		var called []string
+
+		s := newBailTestSpec(&called)
+		example.setup(s)
+
+		r := s.Validate(newDummyform())
+
+		if !reflect.DeepEqual(called, example.called) {
+			t.Errorf("expected rules %v to be called, but got %v", example.called, called)
+		}
+
+		if messages := testBailMessages(r); !reflect.DeepEqual(messages, example.expected) {
+			t.Errorf("expected errors %v, but got %v", example.expected, messages)
+		}
+
+		// Results are same in concurrent mode.
+		s.Concurrency = 4
+
+		r, err := s.ValidateContext(context.Background(), newDummyform())
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if messages := testBailMessages(r); !reflect.DeepEqual(messages, example.expected) {
+			t.Errorf("expected errors %v in concurrent mode, but got %v", example.expected, messages)
+		}
+	}
+}
+
+func TestBail_Declarative(t *testing.T) {
+	type signUp struct {
+		Name string `formspec:"name,required,minlen=3,bail"`
+	}
+
+	fromStruct, err := NewFromStruct(&signUp{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fromYAML, err := ParseYAML([]byte("fail_fast: false\nfields:\n  - field: name\n    bail: true\n    rules: [required, \"minlen:3\"]\n"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []*Formspec{fromStruct, fromYAML} {
+		if r := s.Validate(newDummyform()); len(r.Errors) != 1 || r.Errors[0].Code != "required" {
+			t.Errorf("expected only `required` error, but got %v", r.Errors)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package formspec
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// Default messages for ValidateInto
+
+	BindMessageBool = "must be boolean."
+
+	// Default layout for time.Time fields. You can override by `layout=...` in `formspec` tag.
+	BindTimeLayout = time.RFC3339
+
+	timeType = reflect.TypeOf(time.Time{})
+)
+
+// ValidateInto validates the form, and decodes filtered values into the struct that dst points to.
+// Fields are matched by the name in `formspec` tag (or the name of struct field) like NewFromStruct.
+// Fields without the tag (and fields tagged with `formspec:"-"`) are left as they are.
+// Supported field types are string, int*, uint*, float*, bool, time.Time, pointers and slices of them.
+//
+// Fields that failed validation are not decoded, and conversion failures are reported in the Result as well as rule errors.
+// Messages of conversion failures are built like ones of rules, with labels of rules and the spec, and templates (See Formspec.Template).
+// The returned error is not nil only when dst is not a pointer to struct or it has fields of unsupported types.
+func (f *Formspec) ValidateInto(form Form, dst interface{}) (*Result, error) {
+	return f.ValidateIntoLocale(form, "", dst)
+}
+
+// ValidateIntoLocale is ValidateInto that builds error messages in the locale. See ValidateLocale.
+func (f *Formspec) ValidateIntoLocale(form Form, locale string, dst interface{}) (*Result, error) {
+	v := reflect.ValueOf(dst)
+
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("formspec: ValidateInto requires pointer to struct, but got %T", dst)
+	}
+
+	m := f.messenger(locale)
+	r := f.validateAll(form, m)
+
+	// Conversion failures are not of rules, but they are labeled by labels of rules too.
+	m.labelOf = f.ruleLabelOf
+
+	failed := map[string]bool{}
+
+	for _, err := range r.Errors {
+		failed[err.Field] = true
+	}
+
+	if err := f.bindStruct(form, v.Elem(), failed, m, r); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// ruleLabelOf returns the label of the first rule of the field that has one (See Rule.Label), or the label of the field.
+func (f *Formspec) ruleLabelOf(field string) string {
+	for _, rule := range f.Rules {
+		if rule.Field == field && rule.label != "" {
+			return rule.label
+		}
+	}
+
+	return f.LabelOf(field)
+}
+
+func (f *Formspec) bindStruct(form Form, v reflect.Value, failed map[string]bool, m *messenger, r *Result) error {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, ok := sf.Tag.Lookup("formspec")
+
+		// Fields without the tag are never decoded, so that the form can't set fields that the spec doesn't know.
+		if !ok {
+			if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+				if err := f.bindStruct(form, v.Field(i), failed, m, r); err != nil {
+					return err
+				}
+			}
+
+			continue
+		}
+
+		if tag == "-" || sf.PkgPath != "" {
+			continue
+		}
+
+		field, layout := sf.Name, BindTimeLayout
+		elems, err := splitTag(tag)
+
+		if err != nil {
+			return fmt.Errorf("formspec: %s.%s: %v", t.Name(), sf.Name, err)
+		}
+
+		if elems[0] != "" {
+			field = elems[0]
+		}
+
+		for _, elem := range elems[1:] {
+			if strings.HasPrefix(elem, "layout=") {
+				layout = strings.TrimPrefix(elem, "layout=")
+			}
+		}
+
+		if !bindable(sf.Type) {
+			return fmt.Errorf("formspec: %s.%s: unsupported type %s", t.Name(), sf.Name, sf.Type)
+		}
+
+		if failed[field] {
+			continue
+		}
+
+		if err := f.bindField(form, field, layout, v.Field(i)); err != nil {
+			r.Ok = false
+			r.Errors = append(r.Errors, m.fieldError(field, err))
+		}
+	}
+
+	return nil
+}
+
+func (f *Formspec) bindField(form Form, field, layout string, v reflect.Value) *RuleError {
+	if v.Kind() == reflect.Slice {
+		values := formValues(form, field)
+		s := reflect.MakeSlice(v.Type(), 0, len(values))
+
+		for _, value := range values {
+			e := reflect.New(v.Type().Elem()).Elem()
+
+			if err := decodeValue(f.filterValue(field, value), layout, e); err != nil {
+				return err
+			}
+
+			s = reflect.Append(s, e)
+		}
+
+		v.Set(s)
+		return nil
+	}
+
+	value := f.filterValue(field, form.FormValue(field))
+
+	// Blank value leaves the field as it is.
+	if value == "" {
+		return nil
+	}
+
+	return decodeValue(value, layout, v)
+}
+
+func decodeValue(value, layout string, v reflect.Value) *RuleError {
+	if v.Kind() == reflect.Ptr {
+		p := reflect.New(v.Type().Elem())
+
+		if err := decodeValue(value, layout, p.Elem()); err != nil {
+			return err
+		}
+
+		v.Set(p)
+		return nil
+	}
+
+	if v.Type() == timeType {
+		t, err := time.Parse(layout, value)
+
+		if err != nil {
+			return ruleErrorf("time", map[string]interface{}{"layout": layout}, RuleMessageTime, layout)
+		}
+
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, v.Type().Bits())
+
+		if err != nil {
+			return ruleErrorf("int", nil, RuleMessageInt)
+		}
+
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := strconv.ParseUint(value, 10, v.Type().Bits())
+
+		if err != nil {
+			return ruleErrorf("int", nil, RuleMessageInt)
+		}
+
+		v.SetUint(i)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, v.Type().Bits())
+
+		if err != nil {
+			return ruleErrorf("number", nil, RuleMessageNumber)
+		}
+
+		v.SetFloat(n)
+	case reflect.Bool:
+		b, err := parseBool(value)
+
+		if err != nil {
+			return ruleErrorf("bool", nil, BindMessageBool)
+		}
+
+		v.SetBool(b)
+	}
+
+	return nil
+}
+
+func bindable(t reflect.Type) bool {
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+
+	return false
+}
+
+// parseBool is strconv.ParseBool that accepts also checkbox values "on" and "off".
+func parseBool(value string) (bool, error) {
+	switch value {
+	case "on":
+		return true, nil
+	case "off":
+		return false, nil
+	}
+
+	return strconv.ParseBool(value)
+}
@@ -0,0 +1,179 @@
+package formspec
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+type bindTestEmbedded struct {
+	Note string `formspec:"note"`
+}
+
+type bindTestUser struct {
+	bindTestEmbedded
+	Name     string    `formspec:"name,required"`
+	Age      int       `formspec:"age"`
+	Rate     float64   `formspec:"rate"`
+	Admin    bool      `formspec:"admin"`
+	Born     time.Time `formspec:"born,layout=2006-01-02"`
+	Nick     *string   `formspec:"nick"`
+	IDs      []uint    `formspec:"ids"`
+	Untagged string
+	IsAdmin  bool
+	Extra    map[string]string
+	Ignored  string `formspec:"-"`
+}
+
+func TestValidateInto(t *testing.T) {
+	s := New()
+	s.Rule("name", RuleRequired()).Filter(strings.TrimSpace)
+
+	f := Values(url.Values{
+		"name":     {"  toqoz  "},
+		"age":      {"22"},
+		"rate":     {"1.5"},
+		"admin":    {"on"},
+		"born":     {"1991-04-03"},
+		"nick":     {"tq"},
+		"ids":      {"1", "2"},
+		"note":     {"hi"},
+		"Untagged": {"u"},
+		"IsAdmin":  {"true"},
+		"Ignored":  {"i"},
+	})
+
+	u := &bindTestUser{}
+	r, err := s.ValidateInto(f, u)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !r.Ok {
+		t.Fatalf("validation error is not expected, but got %v", r.Errors)
+	}
+
+	if u.Name != "toqoz" {
+		t.Errorf("expected filtered name `toqoz`, but got `%s`", u.Name)
+	}
+
+	if u.Age != 22 || u.Rate != 1.5 || !u.Admin || u.Note != "hi" || u.Ignored != "" {
+		t.Errorf("unexpected decoded values %+v", u)
+	}
+
+	// Fields without the tag are left untouched.
+	if u.Untagged != "" || u.IsAdmin || u.Extra != nil {
+		t.Errorf("expected untagged fields not to be decoded, but got %+v", u)
+	}
+
+	if !u.Born.Equal(time.Date(1991, 4, 3, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected born 1991-04-03, but got %v", u.Born)
+	}
+
+	if u.Nick == nil || *u.Nick != "tq" {
+		t.Errorf("expected nick `tq`, but got %v", u.Nick)
+	}
+
+	if len(u.IDs) != 2 || u.IDs[0] != 1 || u.IDs[1] != 2 {
+		t.Errorf("expected ids [1 2], but got %v", u.IDs)
+	}
+}
+
+func TestValidateInto_ConversionErrors(t *testing.T) {
+	s := New()
+	s.Rule("name", RuleRequired())
+
+	f := Values(url.Values{
+		"age":   {"x"},
+		"admin": {"maybe"},
+		"born":  {"yesterday"},
+	})
+
+	u := &bindTestUser{}
+	r, err := s.ValidateInto(f, u)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"name is required.",
+		"age must be integer.",
+		"admin must be boolean.",
+		"born must be time formatted as 2006-01-02.",
+	}
+
+	if r.Ok || len(r.Errors) != len(expected) {
+		t.Fatalf("expected %d errors, but got %v", len(expected), r.Errors)
+	}
+
+	for i, e := range expected {
+		if r.Errors[i].Message != e {
+			t.Errorf("expected error `%s`, but got `%s`", e, r.Errors[i].Message)
+		}
+	}
+}
+
+func TestValidateInto_Messages(t *testing.T) {
+	s := New()
+	s.Rule("name", RuleRequired())
+	s.Rule("born", RuleRequired()).Label("Birthday")
+	s.Label("age", "Age")
+	s.Template("int", "{{.Label}} must be a whole number.")
+
+	f := Values(url.Values{
+		"name":  {"toqoz"},
+		"age":   {"x"},
+		"admin": {"maybe"},
+		"born":  {"yesterday"},
+	})
+
+	r, err := s.ValidateInto(f, &bindTestUser{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"Age must be a whole number.",
+		"admin must be boolean.",
+		"Birthday must be time formatted as 2006-01-02.",
+	}
+
+	if len(r.Errors) != len(expected) {
+		t.Fatalf("expected %d errors, but got %v", len(expected), r.Errors)
+	}
+
+	for i, e := range expected {
+		if r.Errors[i].Message != e {
+			t.Errorf("expected error `%s`, but got `%s`", e, r.Errors[i].Message)
+		}
+	}
+
+	// Messages are localized by the catalog.
+	r, err = s.ValidateIntoLocale(f, "ja", &bindTestUser{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(r.Errors) != 3 || r.Errors[1].Message != "adminは真偽値で入力してください。" || r.Errors[1].Code != "bool" {
+		t.Errorf("expected localized errors, but got %v", r.Errors)
+	}
+}
+
+func TestValidateInto_InvalidDestination(t *testing.T) {
+	s := New()
+
+	if _, err := s.ValidateInto(newDummyform(), bindTestUser{}); err == nil {
+		t.Error("expected error for non-pointer destination")
+	}
+
+	if _, err := s.ValidateInto(newDummyform(), &struct {
+		C chan int `formspec:"c"`
+	}{}); err == nil {
+		t.Error("expected error for unsupported field type")
+	}
+}
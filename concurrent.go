@@ -0,0 +1,147 @@
+package formspec
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// runConcurrently calls at most f.Concurrency chains of rules (See Formspec.chains) concurrently.
+// Rules that are running are canceled through the context when validation stops, i.e. at the first InternalError
+// if abort is true, or at the first failure in declared order in FailFast mode.
+func (f *Formspec) runConcurrently(ctx context.Context, form Form, m *messenger, abort bool) ([]outcome, *InternalError) {
+	// *http.Request parses its body at the first call of FormValue. Parse it before rules read it concurrently.
+	// Mounted specs (See Formspec.Mount) read the request through their sub forms.
+	root := form
+
+	if s, ok := form.(*subForm); ok {
+		root = s.root
+	}
+
+	if r, ok := root.(*http.Request); ok {
+		r.FormValue("")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	outcomes := make([]outcome, len(f.Rules))
+	sem := make(chan struct{}, f.Concurrency)
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		first   *InternalError
+		stopped bool
+		// In FailFast mode, validation stops at the first failure in declared order. A failure stops it only after
+		// all rules declared before it are settled (i.e. called, or never called because their fields bail).
+		settled   = make([]bool, len(f.Rules))
+		unsettled int
+		failedAt  = len(f.Rules)
+	)
+
+	stop := func() {
+		stopped = true
+		cancel()
+	}
+
+	// settle records the outcome of the rule. mu must be locked.
+	settle := func(i int, o outcome) {
+		// Rules canceled by the stop are neither invalid nor failed.
+		if stopped {
+			return
+		}
+
+		outcomes[i] = o
+		settled[i] = true
+
+		for unsettled < len(settled) && settled[unsettled] {
+			unsettled++
+		}
+
+		if o.ierr != nil && first == nil {
+			first = o.ierr
+
+			if abort {
+				stop()
+			}
+		}
+
+		if o.failed() && i < failedAt {
+			failedAt = i
+		}
+
+		if f.FailFast && failedAt < len(f.Rules) && unsettled >= failedAt {
+			stop()
+		}
+	}
+
+	for _, chain := range f.chains() {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+
+		mu.Lock()
+		done := stopped
+		mu.Unlock()
+
+		if done {
+			break
+		}
+
+		if err := ctx.Err(); err != nil {
+			// The parent context is done.
+			mu.Lock()
+			settle(chain[0], outcome{ierr: &InternalError{Field: f.Rules[chain[0]].Field, Err: err}})
+
+			for _, i := range chain[1:] {
+				settle(i, outcome{})
+			}
+
+			mu.Unlock()
+			continue
+		}
+
+		wg.Add(1)
+
+		go func(chain []int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			for k, i := range chain {
+				var o outcome
+				o.err, o.ierr = f.Rules[i].call(ctx, form, m)
+
+				mu.Lock()
+				settle(i, o)
+
+				// Chains that have more than one rule are of fields that bail. Rules after the failure are not called.
+				if o.failed() {
+					for _, j := range chain[k+1:] {
+						settle(j, outcome{})
+					}
+				}
+
+				mu.Unlock()
+
+				if o.failed() {
+					return
+				}
+			}
+		}(chain)
+	}
+
+	wg.Wait()
+
+	if first == nil {
+		// The parent context is done before any rule fails.
+		for _, o := range outcomes {
+			if o.ierr != nil {
+				return outcomes, o.ierr
+			}
+		}
+	}
+
+	return outcomes, first
+}
@@ -0,0 +1,175 @@
+package formspec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+// Test formspec.Concurrency
+// -----------------------------------------------------------------------------
+
+func TestConcurrency(t *testing.T) {
+	var running, maxRunning int32
+
+	slow := func(d time.Duration) ContextRuleFunc {
+		return func(ctx context.Context, value string, f Form) error {
+			n := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+
+			for {
+				m := atomic.LoadInt32(&maxRunning)
+
+				if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+					break
+				}
+			}
+
+			time.Sleep(d)
+			return errors.New("is invalid.")
+		}
+	}
+
+	s := New()
+	s.Concurrency = 3
+
+	// Later rules finish earlier.
+	for i := 0; i < 8; i++ {
+		s.ContextRule(fmt.Sprintf("f%d", i), slow(time.Duration(8-i)*2*time.Millisecond))
+	}
+
+	r, err := s.ValidateContext(context.Background(), newDummyform())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(r.Errors) != 8 {
+		t.Fatalf("expected 8 errors, but got %v", r.Errors)
+	}
+
+	for i, e := range r.Errors {
+		if e.Field != fmt.Sprintf("f%d", i) {
+			t.Errorf("expected errors in declared order, but got %s at %d", e.Field, i)
+		}
+	}
+
+	if maxRunning > 3 || maxRunning < 2 {
+		t.Errorf("expected at most 3 rules running concurrently, but got %d", maxRunning)
+	}
+
+	// Validate runs rules concurrently too.
+	if r := s.Validate(newDummyform()); len(r.Errors) != 8 || r.Errors[7].Field != "f7" {
+		t.Errorf("expected 8 errors in declared order, but got %v", r.Errors)
+	}
+}
+
+func TestConcurrency_Abort(t *testing.T) {
+	s := New()
+	s.Concurrency = 2
+	s.ContextRule("slow", func(ctx context.Context, value string, f Form) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+			return nil
+		}
+	})
+	s.ContextRule("email", testEmailNotTaken)
+	s.ContextRule("never", func(ctx context.Context, value string, f Form) error {
+		t.Error("rules after the abort must not be called")
+		return nil
+	})
+
+	start := time.Now()
+	r, err := s.ValidateContext(context.Background(), newDummyform().Set("email", "down@example.com"))
+
+	if r != nil || !errors.Is(err, errTestDBDown) || err.(*InternalError).Field != "email" {
+		t.Errorf("expected (nil, InternalError of email), but got (%v, %v)", r, err)
+	}
+
+	if time.Since(start) > 500*time.Millisecond {
+		t.Errorf("expected running rules to be canceled")
+	}
+}
+
+func TestConcurrency_FailFast(t *testing.T) {
+	done := make(chan struct{})
+
+	s := New()
+	s.FailFast = true
+	s.Concurrency = 2
+	// The rule declared first fails after the second one.
+	s.Rule("a", func(value string, f Form) error {
+		<-done
+		time.Sleep(10 * time.Millisecond)
+		return errors.New("is invalid.")
+	})
+	s.Rule("b", func(value string, f Form) error {
+		defer close(done)
+		return errors.New("is invalid.")
+	})
+
+	for i := 0; i < 5; i++ {
+		r, err := s.ValidateContext(context.Background(), newDummyform())
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(r.Errors) != 1 || r.Errors[0].Field != "a" {
+			t.Fatalf("expected only the error of `a`, but got %v", r.Errors)
+		}
+
+		done = make(chan struct{})
+	}
+}
+
+func TestConcurrency_Request(t *testing.T) {
+	s := New()
+	s.Concurrency = 4
+	s.Rule("name", RuleRequired())
+	s.Rule("name", RuleMaxLen(3))
+	s.MultiRule("tags", RuleMaxItems(1))
+	s.Rule("age", RuleInt())
+
+	body := url.Values{"name": {"toqoz"}, "tags": {"a", "b"}, "age": {"20"}}.Encode()
+	req, _ := http.NewRequest("POST", "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	r := s.Validate(req)
+
+	if len(r.Errors) != 2 || r.Errors[0].Code != "max_len" || r.Errors[1].Code != "max_items" {
+		t.Errorf("expected errors of max_len and max_items, but got %v", r.Errors)
+	}
+}
+
+func TestConcurrency_MountedRequest(t *testing.T) {
+	child := New()
+	child.Concurrency = 4
+	child.Rule("street", RuleRequired())
+	child.Rule("zip", RuleInt())
+	child.Rule("city", RuleMaxLen(3))
+	child.Rule("country", RuleRequired())
+
+	s := New()
+	s.Mount("address", child)
+
+	// Run it with -race. Rules of the mounted spec read the request through the sub form concurrently.
+	body := url.Values{"address.street": {"a"}, "address.zip": {"x"}, "address.city": {"tokyo"}, "address.country": {"JP"}}.Encode()
+	req, _ := http.NewRequest("POST", "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	r := s.Validate(req)
+
+	if len(r.Errors) != 2 || r.Errors[0].Field != "address.zip" || r.Errors[1].Field != "address.city" {
+		t.Errorf("expected errors of address.zip and address.city, but got %v", r.Errors)
+	}
+}
@@ -0,0 +1,121 @@
+package formspec
+
+import (
+	"regexp"
+)
+
+// Operators of Condition
+const (
+	ConditionEquals  = "equals"
+	ConditionMatches = "matches"
+	ConditionPresent = "present"
+	ConditionAbsent  = "absent"
+)
+
+// Condition is a condition on another field of the form. It is used by Rule.When/Rule.Unless.
+type Condition struct {
+	Field string `json:"field"`
+	Op    string `json:"op"`
+	// Value is the value for ConditionEquals, or the pattern for ConditionMatches.
+	Value string `json:"value,omitempty"`
+	// Negated is true when the condition is given to Rule.Unless.
+	Negated bool `json:"negated,omitempty"`
+
+	re *regexp.Regexp
+}
+
+// IfEquals returns the condition that matches when value of the field equals to the value.
+func IfEquals(field, value string) *Condition {
+	return &Condition{Field: field, Op: ConditionEquals, Value: value}
+}
+
+// IfMatches returns the condition that matches when value of the field matches the regexp.
+func IfMatches(field string, re *regexp.Regexp) *Condition {
+	return &Condition{Field: field, Op: ConditionMatches, Value: re.String(), re: re}
+}
+
+// IfPresent returns the condition that matches when value of the field is not blank.
+func IfPresent(field string) *Condition {
+	return &Condition{Field: field, Op: ConditionPresent}
+}
+
+// IfAbsent returns the condition that matches when value of the field is blank.
+func IfAbsent(field string) *Condition {
+	return &Condition{Field: field, Op: ConditionAbsent}
+}
+
+// Match reports whether the condition matches the form.
+func (c *Condition) Match(f Form) bool {
+	v := f.FormValue(c.Field)
+
+	var matched bool
+
+	switch c.Op {
+	case ConditionEquals:
+		matched = v == c.Value
+	case ConditionMatches:
+		re := c.re
+
+		// Conditions that are not made by IfMatches nor given to Rule.When have only the pattern. Invalid patterns match nothing.
+		if re == nil {
+			re, _ = regexp.Compile(c.Value)
+		}
+
+		matched = re != nil && re.MatchString(v)
+	case ConditionPresent:
+		matched = v != ""
+	case ConditionAbsent:
+		matched = v == ""
+	}
+
+	return matched != c.Negated
+}
+
+// When makes the rule be applied only when the condition matches.
+// If it is called multiple times, the rule is applied only when all conditions match.
+// The pattern of ConditionMatches is compiled here. It panics if the pattern is invalid.
+//
+//	s.Rule("zip", formspec.RuleRequired()).When(formspec.IfEquals("country", "US"))
+//	s.Rule("zip", formspec.RuleRequired()).When(&formspec.Condition{Field: "country", Op: formspec.ConditionMatches, Value: "^US$"})
+func (r *Rule) When(c *Condition) *Rule {
+	if c.Op == ConditionMatches && c.re == nil {
+		compiled := *c
+		compiled.re = regexp.MustCompile(c.Value)
+		c = &compiled
+	}
+
+	r.conditions = append(r.conditions, c)
+	return r
+}
+
+// Unless makes the rule be applied only when the condition doesn't match.
+func (r *Rule) Unless(c *Condition) *Rule {
+	negated := *c
+	negated.Negated = !c.Negated
+	return r.When(&negated)
+}
+
+// Conditions returns the conditions given by Rule.When/Rule.Unless.
+func (r *Rule) Conditions() []*Condition {
+	return r.conditions
+}
+
+func (r *Rule) applicable(f Form) bool {
+	for _, c := range r.conditions {
+		if !c.Match(f) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// RequiredIf adds the rule that the field is required when the condition matches.
+func (f *Formspec) RequiredIf(field string, c *Condition) *Rule {
+	return f.Rule(field, RuleRequired()).When(c)
+}
+
+// RequiredUnless adds the rule that the field is required when the condition doesn't match.
+func (f *Formspec) RequiredUnless(field string, c *Condition) *Rule {
+	return f.Rule(field, RuleRequired()).Unless(c)
+}
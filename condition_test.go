@@ -0,0 +1,157 @@
+package formspec
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+)
+
+func TestRequiredIf(t *testing.T) {
+	s := New()
+	s.RequiredIf("zip", IfEquals("country", "US"))
+
+	examples := []struct {
+		form     *dummyForm
+		expected bool
+	}{
+		{newDummyform().Set("country", "US"), false},
+		{newDummyform().Set("country", "US").Set("zip", "12345"), true},
+		{newDummyform().Set("country", "JP"), true},
+		{newDummyform(), true},
+	}
+
+	for _, example := range examples {
+		if r := s.Validate(example.form); r.Ok != example.expected {
+			t.Errorf("Test RequiredIf: When %v is given, expected result is (_, %v). But got (_, %v).", example.form.form, example.expected, r.Ok)
+		}
+	}
+}
+
+func TestRequiredUnless(t *testing.T) {
+	s := New()
+	s.RequiredUnless("email", IfPresent("phone")).FullMessage("Please enter email or phone.")
+
+	if r := s.Validate(newDummyform().Set("phone", "000")); !r.Ok {
+		t.Errorf("validation error is not expected, but got %v", r.Errors)
+	}
+
+	r := s.Validate(newDummyform())
+
+	if r.Ok || r.Errors[0].Message != "Please enter email or phone." {
+		t.Errorf("expected error `Please enter email or phone.`, but got %v", r.Errors)
+	}
+}
+
+func TestWhen(t *testing.T) {
+	s := New()
+	s.Rule("code", RuleMaxLen(3)).When(IfMatches("kind", regexp.MustCompile(`\Ashort`))).When(IfAbsent("admin"))
+	s.Rule("code", RuleInt()).Unless(IfEquals("kind", "text")).AllowBlank()
+
+	examples := []struct {
+		form     *dummyForm
+		expected bool
+	}{
+		{newDummyform().Set("kind", "short-code").Set("code", "1234"), false},
+		{newDummyform().Set("kind", "short-code").Set("code", "1234").Set("admin", "1"), true},
+		{newDummyform().Set("kind", "long-code").Set("code", "1234"), true},
+		{newDummyform().Set("kind", "long-code").Set("code", "abcd"), false},
+		{newDummyform().Set("kind", "text").Set("code", "abcd"), true},
+		{newDummyform().Set("kind", "long-code"), true},
+	}
+
+	for _, example := range examples {
+		if r := s.Validate(example.form); r.Ok != example.expected {
+			t.Errorf("Test When: When %v is given, expected result is (_, %v). But got (_, %v).", example.form.form, example.expected, r.Ok)
+		}
+	}
+}
+
+func TestCondition_MatchesLiteral(t *testing.T) {
+	c := &Condition{Field: "country", Op: ConditionMatches, Value: "^US$"}
+
+	if !c.Match(newDummyform().Set("country", "US")) || c.Match(newDummyform().Set("country", "JP")) {
+		t.Error("expected the pattern of the condition literal to be used")
+	}
+
+	if (&Condition{Field: "country", Op: ConditionMatches, Value: "("}).Match(newDummyform().Set("country", "(")) {
+		t.Error("expected the invalid pattern to match nothing")
+	}
+
+	s := New()
+	s.Rule("zip", RuleRequired()).Unless(c)
+
+	if r := s.Validate(newDummyform().Set("country", "JP")); r.Ok {
+		t.Error("expected validation error")
+	}
+
+	if r := s.Validate(newDummyform().Set("country", "US")); !r.Ok {
+		t.Errorf("validation error is not expected, but got %v", r.Errors)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for the invalid pattern")
+		}
+	}()
+
+	s.Rule("zip", RuleRequired()).When(&Condition{Field: "country", Op: ConditionMatches, Value: "("})
+}
+
+func TestConditions_Clone(t *testing.T) {
+	s := New()
+	s.Rule("code", RuleRequired()).When(IfPresent("a")).When(IfPresent("b")).When(IfPresent("c"))
+
+	a, b := s.Clone(), s.Clone()
+	a.Rules[0].When(IfPresent("x"))
+	b.Rules[0].When(IfPresent("y"))
+
+	if c := a.Rules[0].Conditions(); len(c) != 4 || c[3].Field != "x" {
+		t.Errorf("expected the condition on x, but got %+v", c)
+	}
+
+	if c := b.Rules[0].Conditions(); len(c) != 4 || c[3].Field != "y" {
+		t.Errorf("expected the condition on y, but got %+v", c)
+	}
+
+	if c := s.Rules[0].Conditions(); len(c) != 3 {
+		t.Errorf("expected conditions of the original not to be changed, but got %+v", c)
+	}
+}
+
+func TestConditions_Introspection(t *testing.T) {
+	s := New()
+	rule := s.RequiredUnless("email", IfPresent("phone"))
+
+	j, _ := json.Marshal(rule.Conditions())
+
+	if expected := `[{"field":"phone","op":"present","negated":true}]`; string(j) != expected {
+		t.Errorf("expected %s, but got %s", expected, j)
+	}
+
+	if c := s.Clone().Rules[0].Conditions(); len(c) != 1 {
+		t.Errorf("expected cloned rule to have the condition, but got %v", c)
+	}
+}
+
+func TestConditions_JSONSchema(t *testing.T) {
+	s := New()
+	s.RequiredIf("zip", IfEquals("country", "US"))
+	s.Rule("zip", RuleMaxLen(5)).When(IfEquals("country", "US"))
+	s.RequiredUnless("email", IfPresent("phone"))
+
+	j, err := json.Marshal(s.JSONSchema())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object",` +
+		`"properties":{"email":{"type":"string"},"zip":{"type":"string"}},"allOf":[` +
+		`{"if":{"properties":{"country":{"const":"US"}},"required":["country"]},"then":{"required":["zip"]}},` +
+		`{"if":{"properties":{"country":{"const":"US"}},"required":["country"]},"then":{"properties":{"zip":{"maxLength":5}}}},` +
+		`{"if":{"not":{"properties":{"phone":{"minLength":1}},"required":["phone"]}},"then":{"required":["email"]}}]}`
+
+	if string(j) != expected {
+		t.Errorf("expected\n%s\nbut got\n%s", expected, j)
+	}
+}
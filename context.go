@@ -0,0 +1,86 @@
+package formspec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ContextRuleFunc is a RuleFunc that receives the context. Use it for rules that call databases or other services.
+// e.g. "email is not registered yet"
+//
+// Return an error wrapped by Internal when the check itself fails, e.g. the database is down.
+// Errors of the context (context.Canceled and context.DeadlineExceeded) are treated in the same way.
+type ContextRuleFunc func(ctx context.Context, value string, f Form) error
+
+// RuleMessageInternal is used by Validate for rules that failed to check the value. See ContextRuleFunc.
+var RuleMessageInternal = "can't be validated now. Please try again later."
+
+// InternalError is an error that tells the rule failed to check the value.
+// It is not a validation error, and it should be responded as a server error.
+type InternalError struct {
+	Field string
+	Err   error
+}
+
+// Internal wraps the error returned from rule funcs, so that it is treated as InternalError.
+//
+//	s.ContextRule("email", func(ctx context.Context, value string, f formspec.Form) error {
+//		exists, err := users.ExistsByEmail(ctx, value)
+//
+//		if err != nil {
+//			return formspec.Internal(err)
+//		}
+//
+//		if exists {
+//			return formspec.NewRuleError("taken", nil, "is already taken.")
+//		}
+//
+//		return nil
+//	})
+func Internal(err error) error {
+	return &InternalError{Err: err}
+}
+
+func (e *InternalError) Error() string {
+	return fmt.Sprintf("formspec: %s can't be validated: %v", e.Field, e.Err)
+}
+
+func (e *InternalError) Unwrap() error {
+	return e.Err
+}
+
+// internalError returns *InternalError for the field if err is InternalError or an error of the context.
+func internalError(field string, err error) *InternalError {
+	var ierr *InternalError
+
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &ierr):
+		return &InternalError{Field: field, Err: ierr.Err}
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		return &InternalError{Field: field, Err: err}
+	}
+
+	return nil
+}
+
+// internalError returns *Error that has code "internal" for the field. It is used by Validate.
+func (m *messenger) internalError(field string) *Error {
+	return m.fieldError(field, NewRuleError("internal", nil, RuleMessageInternal))
+}
+
+// ContextRule adds a rule that receives the context. See ContextRuleFunc.
+func (f *Formspec) ContextRule(field string, contextRuleFunc ContextRuleFunc) *Rule {
+	rule := &Rule{Field: field, ContextRuleFunc: contextRuleFunc}
+	f.Rules = append(f.Rules, rule)
+	return rule
+}
+
+// ValidateContext validates the form with the context.
+// The returned error is *InternalError when a rule failed to check the value, or the context is done.
+// In that case, the Result is nil because the form is neither valid nor invalid.
+func (f *Formspec) ValidateContext(ctx context.Context, form Form) (*Result, error) {
+	return f.validate(ctx, form, f.messenger(""))
+}
@@ -0,0 +1,100 @@
+package formspec
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+// Test formspec.ContextRule
+// -----------------------------------------------------------------------------
+
+var errTestDBDown = errors.New("db is down")
+
+func testEmailNotTaken(ctx context.Context, value string, f Form) error {
+	switch value {
+	case "down@example.com":
+		return Internal(errTestDBDown)
+	case "slow@example.com":
+		<-ctx.Done()
+		return ctx.Err()
+	case "taken@example.com":
+		return NewRuleError("taken", nil, "is already taken.")
+	}
+
+	return nil
+}
+
+func TestValidateContext(t *testing.T) {
+	s := New()
+	s.Rule("name", RuleRequired())
+	s.ContextRule("email", testEmailNotTaken).Filter(FilterTrim())
+
+	r, err := s.ValidateContext(context.Background(), newDummyform().Set("name", "toqoz").Set("email", " new@example.com "))
+
+	if err != nil || !r.Ok {
+		t.Errorf("expected (ok, nil), but got (%v, %v)", r, err)
+	}
+
+	r, err = s.ValidateContext(context.Background(), newDummyform().Set("email", "taken@example.com"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(r.Errors) != 2 || r.Errors[1].Code != "taken" || r.Errors[1].Message != "email is already taken." {
+		t.Errorf("expected validation error that has code `taken`, but got %v", r.Errors)
+	}
+}
+
+func TestValidateContext_InternalError(t *testing.T) {
+	s := New()
+	s.ContextRule("email", testEmailNotTaken)
+
+	examples := []struct {
+		ctx      func() (context.Context, context.CancelFunc)
+		email    string
+		expected error
+	}{
+		{func() (context.Context, context.CancelFunc) { return context.WithCancel(context.Background()) }, "down@example.com", errTestDBDown},
+		{func() (context.Context, context.CancelFunc) {
+			return context.WithTimeout(context.Background(), time.Millisecond)
+		}, "slow@example.com", context.DeadlineExceeded},
+		{func() (context.Context, context.CancelFunc) {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			return ctx, cancel
+		}, "new@example.com", context.Canceled},
+	}
+
+	for _, example := range examples {
+		ctx, cancel := example.ctx()
+		r, err := s.ValidateContext(ctx, newDummyform().Set("email", example.email))
+		cancel()
+
+		var ierr *InternalError
+
+		if r != nil || !errors.As(err, &ierr) || ierr.Field != "email" || !errors.Is(err, example.expected) {
+			t.Errorf("When `%s` is given, expected (nil, InternalError for %v), but got (%v, %v)", example.email, example.expected, r, err)
+		}
+	}
+}
+
+func TestValidate_InternalError(t *testing.T) {
+	s := New()
+	s.Label("email", "Email")
+	s.ContextRule("email", testEmailNotTaken)
+
+	f := newDummyform().Set("email", "down@example.com")
+	r := s.Validate(f)
+
+	if r.Ok || r.Errors[0].Code != "internal" || r.Errors[0].Message != "Email can't be validated now. Please try again later." {
+		t.Errorf("expected error that has code `internal`, but got %v", r.Errors)
+	}
+
+	if err := s.Rules[0].Call(f); !errors.Is(err, errTestDBDown) {
+		t.Errorf("expected InternalError, but got %v", err)
+	}
+}
@@ -7,13 +7,17 @@ Package github.com/ToQoz/formspec validates a form. So it will expresses **spec*
 
 ExampleApp: https://github.com/ToQoz/go-formspec/tree/master/_example
 
+v2 has breaking changes from v1. Built-in rules and filters return described values (e.g. DescribedRule) instead of funcs,
+and Formspec.Rule, Formspec.MultiRule, Formspec.FileRule and Rule.Filter take them or funcs as interface{}. Values of wrong types panic when rules are added.
+See "Upgrading to v2" in README.md.
+
 Simple usage in http.Handler.
 
 	package main
 
 	import (
 		"fmt"
-		"github.com/ToQoz/go-formspec"
+		"github.com/ToQoz/go-formspec/v2"
 		"log"
 		"net/http"
 	)
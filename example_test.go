@@ -2,7 +2,7 @@ package formspec_test
 
 import (
 	"fmt"
-	"github.com/ToQoz/go-formspec"
+	"github.com/ToQoz/go-formspec/v2"
 )
 
 type exampleForm struct {
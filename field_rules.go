@@ -0,0 +1,153 @@
+package formspec
+
+import (
+	"strconv"
+	"time"
+)
+
+var (
+	// Default messages for rules that compare with another field. %s is replaced with the label of another field.
+
+	RuleMessageEqualToField     = "must be equal to %s."
+	RuleMessageNotEqualToField  = "must not be equal to %s."
+	RuleMessageLessThanField    = "must be less than %s."
+	RuleMessageGreaterThanField = "must be greater than %s."
+	RuleMessageBeforeField      = "must be before %s."
+	RuleMessageAfterField       = "must be after %s."
+	RuleMessageTime             = "must be time formatted as %s."
+)
+
+// funcs that return RuleFunc comparing the value with another field.
+// Except RuleEqualToField, they return no error when value of another field is blank or can't be parsed,
+// because it should be reported by rules of the field.
+
+// RuleEqualToField checks the value equals to value of another field. e.g. password confirmation
+func RuleEqualToField(field string) DescribedRule {
+	return Describe(func(value string, f Form) error {
+		if value != f.FormValue(field) {
+			return fieldRefError("equal_to_field", map[string]interface{}{"field": field}, RuleMessageEqualToField, []string{field})
+		}
+
+		return nil
+	}, "equal_to_field", map[string]interface{}{"field": field})
+}
+
+func RuleNotEqualToField(field string) DescribedRule {
+	return Describe(func(value string, f Form) error {
+		if other := f.FormValue(field); other != "" && value == other {
+			return fieldRefError("not_equal_to_field", map[string]interface{}{"field": field}, RuleMessageNotEqualToField, []string{field})
+		}
+
+		return nil
+	}, "not_equal_to_field", map[string]interface{}{"field": field})
+}
+
+// RuleLessThanField checks the value is number less than number of another field.
+func RuleLessThanField(field string) DescribedRule {
+	return Describe(func(value string, f Form) error {
+		a, b, err := parseNumbers(value, f.FormValue(field))
+
+		if err != nil || b == nil {
+			return err
+		}
+
+		if !(*a < *b) {
+			return fieldRefError("less_than_field", map[string]interface{}{"field": field}, RuleMessageLessThanField, []string{field})
+		}
+
+		return nil
+	}, "less_than_field", map[string]interface{}{"field": field})
+}
+
+// RuleGreaterThanField checks the value is number greater than number of another field.
+func RuleGreaterThanField(field string) DescribedRule {
+	return Describe(func(value string, f Form) error {
+		a, b, err := parseNumbers(value, f.FormValue(field))
+
+		if err != nil || b == nil {
+			return err
+		}
+
+		if !(*a > *b) {
+			return fieldRefError("greater_than_field", map[string]interface{}{"field": field}, RuleMessageGreaterThanField, []string{field})
+		}
+
+		return nil
+	}, "greater_than_field", map[string]interface{}{"field": field})
+}
+
+// RuleBeforeField checks the value is time before time of another field. Both are parsed by the layout.
+func RuleBeforeField(field, layout string) DescribedRule {
+	return Describe(func(value string, f Form) error {
+		a, b, err := parseTimes(value, f.FormValue(field), layout)
+
+		if err != nil || b == nil {
+			return err
+		}
+
+		if !a.Before(*b) {
+			return fieldRefError("before_field", map[string]interface{}{"field": field, "layout": layout}, RuleMessageBeforeField, []string{field})
+		}
+
+		return nil
+	}, "before_field", map[string]interface{}{"field": field, "layout": layout})
+}
+
+// RuleAfterField checks the value is time after time of another field. e.g. end_date after start_date
+func RuleAfterField(field, layout string) DescribedRule {
+	return Describe(func(value string, f Form) error {
+		a, b, err := parseTimes(value, f.FormValue(field), layout)
+
+		if err != nil || b == nil {
+			return err
+		}
+
+		if !a.After(*b) {
+			return fieldRefError("after_field", map[string]interface{}{"field": field, "layout": layout}, RuleMessageAfterField, []string{field})
+		}
+
+		return nil
+	}, "after_field", map[string]interface{}{"field": field, "layout": layout})
+}
+
+// parseNumbers parses the value and value of another field. other is nil when it can't be parsed.
+func parseNumbers(value, otherValue string) (a, other *float64, err error) {
+	if !RuleFormatNumber.MatchString(value) {
+		return nil, nil, ruleErrorf("number", nil, RuleMessageNumber)
+	}
+
+	v, err := strconv.ParseFloat(value, 64)
+
+	if err != nil {
+		return nil, nil, ruleErrorf("number", nil, RuleMessageNumber)
+	}
+
+	if !RuleFormatNumber.MatchString(otherValue) {
+		return &v, nil, nil
+	}
+
+	o, err := strconv.ParseFloat(otherValue, 64)
+
+	if err != nil {
+		return &v, nil, nil
+	}
+
+	return &v, &o, nil
+}
+
+// parseTimes parses the value and value of another field. other is nil when it can't be parsed.
+func parseTimes(value, otherValue, layout string) (a, other *time.Time, err error) {
+	v, err := time.Parse(layout, value)
+
+	if err != nil {
+		return nil, nil, ruleErrorf("time", map[string]interface{}{"layout": layout}, RuleMessageTime, layout)
+	}
+
+	o, err := time.Parse(layout, otherValue)
+
+	if err != nil {
+		return &v, nil, nil
+	}
+
+	return &v, &o, nil
+}
@@ -0,0 +1,108 @@
+package formspec
+
+import (
+	"testing"
+)
+
+type fieldRuleTestExample struct {
+	value    string
+	other    string
+	expected bool
+}
+
+func testFieldRule(t *testing.T, name string, ruleFunc DescribedRule, examples []fieldRuleTestExample) {
+	s := New()
+	s.Rule("a", ruleFunc)
+
+	for _, example := range examples {
+		f := newDummyform().Set("a", example.value).Set("b", example.other)
+
+		if r := s.Validate(f); r.Ok != example.expected {
+			t.Errorf("Test %s: When a=`%s` and b=`%s` are given, expected result is (_, %v). But got (_, %v).", name, example.value, example.other, example.expected, r.Ok)
+		}
+	}
+}
+
+func TestRuleEqualToField(t *testing.T) {
+	testFieldRule(t, "RuleEqualToField", RuleEqualToField("b"), []fieldRuleTestExample{
+		{"x", "x", true}, {"", "", true},
+		{"x", "y", false}, {"x", "", false}, {"", "x", false},
+	})
+}
+
+func TestRuleNotEqualToField(t *testing.T) {
+	testFieldRule(t, "RuleNotEqualToField", RuleNotEqualToField("b"), []fieldRuleTestExample{
+		{"x", "y", true}, {"x", "", true},
+		{"x", "x", false},
+	})
+}
+
+func TestRuleLessThanField(t *testing.T) {
+	testFieldRule(t, "RuleLessThanField", RuleLessThanField("b"), []fieldRuleTestExample{
+		{"1", "2", true}, {"1.5", "2", true}, {"1", "x", true}, {"1", "", true},
+		{"2", "2", false}, {"3", "2", false}, {"x", "2", false},
+	})
+}
+
+func TestRuleGreaterThanField(t *testing.T) {
+	testFieldRule(t, "RuleGreaterThanField", RuleGreaterThanField("b"), []fieldRuleTestExample{
+		{"3", "2", true}, {"2.5", "2", true}, {"1", "", true},
+		{"2", "2", false}, {"1", "2", false}, {"x", "2", false},
+	})
+}
+
+func TestRuleBeforeField(t *testing.T) {
+	testFieldRule(t, "RuleBeforeField", RuleBeforeField("b", "2006-01-02"), []fieldRuleTestExample{
+		{"2014-01-01", "2014-01-02", true}, {"2014-01-01", "", true},
+		{"2014-01-02", "2014-01-02", false}, {"2014-01-03", "2014-01-02", false}, {"x", "2014-01-02", false},
+	})
+}
+
+func TestRuleAfterField(t *testing.T) {
+	testFieldRule(t, "RuleAfterField", RuleAfterField("b", "2006-01-02"), []fieldRuleTestExample{
+		{"2014-01-03", "2014-01-02", true}, {"2014-01-01", "x", true},
+		{"2014-01-02", "2014-01-02", false}, {"2014-01-01", "2014-01-02", false},
+	})
+}
+
+func TestFieldRule_Messages(t *testing.T) {
+	s := New()
+	s.Rule("password_confirmation", RuleEqualToField("password"))
+	s.Rule("end_date", RuleAfterField("start_date", "2006-01-02"))
+
+	f := newDummyform().Set("password", "a").Set("password_confirmation", "b").Set("start_date", "2014-01-02").Set("end_date", "2014-01-01")
+
+	expected := []string{
+		"password_confirmation must be equal to password.",
+		"end_date must be after start_date.",
+	}
+
+	r := s.Validate(f)
+
+	for i, e := range expected {
+		if r.Errors[i].Message != e {
+			t.Errorf("expected error `%s`, but got `%s`", e, r.Errors[i].Message)
+		}
+	}
+
+	// with labels
+	s.Label("password", "Password").Label("password_confirmation", "Password confirmation")
+	s.Label("start_date", "Start date").Label("end_date", "End date")
+
+	expected = []string{
+		"Password confirmation must be equal to Password.",
+		"End date must be after Start date.",
+	}
+
+	r = s.Validate(f)
+
+	for i, e := range expected {
+		if r.Errors[i].Message != e {
+			t.Errorf("expected error `%s`, but got `%s`", e, r.Errors[i].Message)
+		}
+	}
+
+	if r := s.Clone().Validate(f); r.Errors[0].Message != expected[0] {
+		t.Errorf("expected cloned spec to have labels, but got `%s`", r.Errors[0].Message)
+	}
+}
@@ -0,0 +1,178 @@
+package formspec
+
+import (
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	// Default messages for files
+
+	RuleMessageFileMaxSize       = "is too large. Max is %d bytes."
+	RuleMessageFileMIMEType      = "has unsupported file type %s."
+	RuleMessageFileExt           = "has unsupported file extension."
+	RuleMessageImage             = "must be PNG, JPEG or GIF image."
+	RuleMessageImageMaxDimension = "is too large. Max is %dx%d pixels."
+	RuleMessageImageMinDimension = "is too small. Min is %dx%d pixels."
+)
+
+// funcs that return FileRuleFunc
+// They must have prefix `Rule` too. All of them except RuleFileRequired returns no error when no file is uploaded.
+
+func RuleFileRequired() DescribedFileRule {
+	return DescribeFile(func(file *multipart.FileHeader, _ Form) error {
+		if file == nil {
+			return ruleErrorf("required", nil, RuleMessageRequired)
+		}
+
+		return nil
+	}, "file_required", nil)
+}
+
+func RuleFileMaxSize(maxSize int64) DescribedFileRule {
+	return DescribeFile(func(file *multipart.FileHeader, _ Form) error {
+		if file != nil && file.Size > maxSize {
+			return ruleErrorf("file_max_size", map[string]interface{}{"max": maxSize}, RuleMessageFileMaxSize, maxSize)
+		}
+
+		return nil
+	}, "file_max_size", map[string]interface{}{"max": maxSize})
+}
+
+// RuleFileMIMEType checks MIME type detected by sniffing the file content (See http.DetectContentType).
+// Content-Type header sent by client is not trusted.
+func RuleFileMIMEType(mimeTypes ...string) DescribedFileRule {
+	return DescribeFile(func(file *multipart.FileHeader, _ Form) error {
+		if file == nil {
+			return nil
+		}
+
+		detected, err := detectContentType(file)
+
+		if err != nil {
+			return err
+		}
+
+		for _, t := range mimeTypes {
+			if t == detected {
+				return nil
+			}
+		}
+
+		return ruleErrorf("file_mime_type", map[string]interface{}{"types": mimeTypes, "detected": detected}, RuleMessageFileMIMEType, detected)
+	}, "file_mime_type", map[string]interface{}{"types": mimeTypes})
+}
+
+// RuleFileExt checks extension of the file name. exts are compared case-insensitively and must have leading dot. e.g. ".png"
+func RuleFileExt(exts ...string) DescribedFileRule {
+	return DescribeFile(func(file *multipart.FileHeader, _ Form) error {
+		if file == nil {
+			return nil
+		}
+
+		ext := filepath.Ext(file.Filename)
+
+		for _, e := range exts {
+			if strings.EqualFold(e, ext) {
+				return nil
+			}
+		}
+
+		return ruleErrorf("file_ext", map[string]interface{}{"exts": exts}, RuleMessageFileExt)
+	}, "file_ext", map[string]interface{}{"exts": exts})
+}
+
+// RuleImageMaxDimension checks width and height of PNG/JPEG/GIF image.
+func RuleImageMaxDimension(maxWidth, maxHeight int) DescribedFileRule {
+	return DescribeFile(func(file *multipart.FileHeader, _ Form) error {
+		if file == nil {
+			return nil
+		}
+
+		config, err := decodeImageConfig(file)
+
+		if err != nil {
+			return err
+		}
+
+		if config.Width > maxWidth || config.Height > maxHeight {
+			return ruleErrorf("image_max_dimension", map[string]interface{}{"width": maxWidth, "height": maxHeight}, RuleMessageImageMaxDimension, maxWidth, maxHeight)
+		}
+
+		return nil
+	}, "image_max_dimension", map[string]interface{}{"width": maxWidth, "height": maxHeight})
+}
+
+// RuleImageMinDimension checks width and height of PNG/JPEG/GIF image.
+func RuleImageMinDimension(minWidth, minHeight int) DescribedFileRule {
+	return DescribeFile(func(file *multipart.FileHeader, _ Form) error {
+		if file == nil {
+			return nil
+		}
+
+		config, err := decodeImageConfig(file)
+
+		if err != nil {
+			return err
+		}
+
+		if config.Width < minWidth || config.Height < minHeight {
+			return ruleErrorf("image_min_dimension", map[string]interface{}{"width": minWidth, "height": minHeight}, RuleMessageImageMinDimension, minWidth, minHeight)
+		}
+
+		return nil
+	}, "image_min_dimension", map[string]interface{}{"width": minWidth, "height": minHeight})
+}
+
+func detectContentType(file *multipart.FileHeader) (string, error) {
+	// Files that can't be read are not invalid. The rule failed to check them.
+	f, err := file.Open()
+
+	if err != nil {
+		return "", Internal(err)
+	}
+
+	defer f.Close()
+
+	// http.DetectContentType considers at most the first 512 bytes.
+	buf := make([]byte, 512)
+	n, err := io.ReadFull(f, buf)
+
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", Internal(err)
+	}
+
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(buf[:n]))
+
+	if err != nil {
+		return "", Internal(err)
+	}
+
+	return mediaType, nil
+}
+
+func decodeImageConfig(file *multipart.FileHeader) (image.Config, error) {
+	f, err := file.Open()
+
+	if err != nil {
+		return image.Config{}, Internal(err)
+	}
+
+	defer f.Close()
+
+	config, _, err := image.DecodeConfig(f)
+
+	if err != nil {
+		return image.Config{}, ruleErrorf("image", nil, RuleMessageImage)
+	}
+
+	return config, nil
+}
@@ -0,0 +1,176 @@
+package formspec
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"testing"
+)
+
+func newMultipartRequest(field, filename string, content []byte) *http.Request {
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+
+	if field != "" {
+		part, _ := w.CreateFormFile(field, filename)
+		part.Write(content)
+	}
+
+	w.Close()
+
+	req, _ := http.NewRequest("POST", "/", body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	return req
+}
+
+func pngBytes(width, height int) []byte {
+	buf := &bytes.Buffer{}
+	png.Encode(buf, image.NewRGBA(image.Rect(0, 0, width, height)))
+	return buf.Bytes()
+}
+
+// -----------------------------------------------------------------------------
+// Test formspec.RuleFileRequired
+// -----------------------------------------------------------------------------
+
+func TestRuleFileRequired(t *testing.T) {
+	s := New()
+	s.FileRule("avatar", RuleFileRequired())
+
+	if r := s.Validate(newMultipartRequest("avatar", "a.png", pngBytes(1, 1))); !r.Ok {
+		t.Error("Test RuleFileRequired(): When file is given, returns no error. But got error.")
+	}
+
+	r := s.Validate(newMultipartRequest("", "", nil))
+
+	if r.Ok {
+		t.Error("Test RuleFileRequired(): When file is not given, returns error. But got no error.")
+		return
+	}
+
+	if r.Errors[0].Message != "avatar is required." {
+		t.Errorf("Test RuleFileRequired(): expected error `avatar is required.`, but got `%s`", r.Errors[0].Message)
+	}
+
+	// form that can't have files
+	if r := s.Validate(newDummyform()); r.Ok {
+		t.Error("Test RuleFileRequired(): When form can't have files, returns error. But got no error.")
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Test formspec.RuleFileMaxSize
+// -----------------------------------------------------------------------------
+
+func TestRuleFileMaxSize(t *testing.T) {
+	s := New()
+	s.FileRule("doc", RuleFileMaxSize(10))
+
+	if r := s.Validate(newMultipartRequest("doc", "a.txt", []byte("0123456789"))); !r.Ok {
+		t.Error("Test RuleFileMaxSize(10): When 10 bytes file is given, returns no error. But got error.")
+	}
+
+	if r := s.Validate(newMultipartRequest("doc", "a.txt", []byte("0123456789a"))); r.Ok {
+		t.Error("Test RuleFileMaxSize(10): When 11 bytes file is given, returns error. But got no error.")
+	}
+
+	if r := s.Validate(newMultipartRequest("", "", nil)); !r.Ok {
+		t.Error("Test RuleFileMaxSize(10): When file is not given, returns no error. But got error.")
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Test formspec.RuleFileMIMEType
+// -----------------------------------------------------------------------------
+
+func TestRuleFileMIMEType(t *testing.T) {
+	s := New()
+	s.FileRule("avatar", RuleFileMIMEType("image/png", "image/gif"))
+
+	if r := s.Validate(newMultipartRequest("avatar", "a.png", pngBytes(1, 1))); !r.Ok {
+		t.Error("Test RuleFileMIMEType(): When png is given, returns no error. But got error.")
+	}
+
+	// content is sniffed, so the file name doesn't matter.
+	if r := s.Validate(newMultipartRequest("avatar", "a.png", []byte("hello"))); r.Ok {
+		t.Error("Test RuleFileMIMEType(): When text named a.png is given, returns error. But got no error.")
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Test formspec.RuleFileExt
+// -----------------------------------------------------------------------------
+
+func TestRuleFileExt(t *testing.T) {
+	s := New()
+	s.FileRule("avatar", RuleFileExt(".png", ".jpg"))
+
+	examples := []ruleTestExample{
+		{"a.png", true}, {"a.PNG", true}, {"a.jpg", true},
+		{"a.gif", false}, {"a", false}, {"png", false},
+	}
+
+	for _, example := range examples {
+		if r := s.Validate(newMultipartRequest("avatar", example.input, []byte("x"))); r.Ok != example.expected {
+			t.Errorf("Test RuleFileExt(): When `%s` is given, expected result is (_, %v). But got (_, %v).", example.input, example.expected, r.Ok)
+		}
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Test formspec.RuleImageMaxDimension/RuleImageMinDimension
+// -----------------------------------------------------------------------------
+
+func TestRuleImageDimension(t *testing.T) {
+	s := New()
+	s.FileRule("avatar", RuleImageMaxDimension(20, 10))
+	s.FileRule("avatar", RuleImageMinDimension(2, 2))
+
+	examples := []struct {
+		width    int
+		height   int
+		expected bool
+	}{
+		{20, 10, true}, {2, 2, true},
+		{21, 10, false}, {20, 11, false}, {1, 2, false}, {2, 1, false},
+	}
+
+	for _, example := range examples {
+		if r := s.Validate(newMultipartRequest("avatar", "a.png", pngBytes(example.width, example.height))); r.Ok != example.expected {
+			t.Errorf("Test RuleImageDimension(): When %dx%d image is given, expected result is (_, %v). But got (_, %v).", example.width, example.height, example.expected, r.Ok)
+		}
+	}
+
+	if r := s.Validate(newMultipartRequest("avatar", "a.png", []byte("not image"))); r.Ok {
+		t.Error("Test RuleImageDimension(): When not image is given, returns error. But got no error.")
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Test files that can't be read
+// -----------------------------------------------------------------------------
+
+func TestFileRules_Internal(t *testing.T) {
+	// The file has neither content nor temporary file, so it can't be opened.
+	file := &multipart.FileHeader{Filename: "a.png", Size: 1}
+
+	examples := []struct {
+		name string
+		rule DescribedFileRule
+	}{
+		{"RuleFileMIMEType", RuleFileMIMEType("image/png")},
+		{"RuleImageMaxDimension", RuleImageMaxDimension(20, 10)},
+		{"RuleImageMinDimension", RuleImageMinDimension(2, 2)},
+	}
+
+	for _, example := range examples {
+		var ierr *InternalError
+
+		if err := example.rule.Func(file, nil); !errors.As(err, &ierr) {
+			t.Errorf("Test %s(): When the file can't be opened, returns InternalError. But got %v.", example.name, err)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package formspec
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// All built-in filters are described by DescribeFilter, so that they appear in introspection. See Rule.FilterMetas.
+
+// FilterTrim removes leading and trailing white spaces.
+func FilterTrim() DescribedFilter {
+	return DescribeFilter(strings.TrimSpace, "trim", nil)
+}
+
+// FilterTrimClass removes leading and trailing characters in the unicode classes.
+// Classes are names of categories, scripts or properties in the unicode package. e.g. "P" (punctuation), "Zs", "White_Space"
+// It panics if the class is unknown.
+//
+//	formspec.FilterTrimClass("White_Space", "P")
+func FilterTrimClass(classes ...string) DescribedFilter {
+	tables, err := rangeTables(classes)
+
+	if err != nil {
+		panic("formspec: " + err.Error())
+	}
+
+	return DescribeFilter(func(value string) string {
+		return strings.TrimFunc(value, func(r rune) bool {
+			return unicode.IsOneOf(tables, r)
+		})
+	}, "trim_class", map[string]interface{}{"classes": classes})
+}
+
+func rangeTables(classes []string) ([]*unicode.RangeTable, error) {
+	tables := make([]*unicode.RangeTable, len(classes))
+
+	for i, class := range classes {
+		var ok bool
+
+		for _, m := range []map[string]*unicode.RangeTable{unicode.Categories, unicode.Scripts, unicode.Properties} {
+			if tables[i], ok = m[class]; ok {
+				break
+			}
+		}
+
+		if !ok {
+			return nil, fmt.Errorf("unknown unicode class %q", class)
+		}
+	}
+
+	return tables, nil
+}
+
+// FilterLower maps all letters to their lower case.
+func FilterLower() DescribedFilter {
+	return DescribeFilter(strings.ToLower, "lower", nil)
+}
+
+// FilterUpper maps all letters to their upper case.
+func FilterUpper() DescribedFilter {
+	return DescribeFilter(strings.ToUpper, "upper", nil)
+}
+
+// FilterCollapseSpace replaces each run of white spaces with a single space. It doesn't trim the value. Use FilterTrim with it.
+func FilterCollapseSpace() DescribedFilter {
+	return DescribeFilter(func(value string) string {
+		var b strings.Builder
+
+		space := false
+
+		for _, r := range value {
+			if unicode.IsSpace(r) {
+				if !space {
+					b.WriteByte(' ')
+				}
+
+				space = true
+				continue
+			}
+
+			b.WriteRune(r)
+			space = false
+		}
+
+		return b.String()
+	}, "collapse_space", nil)
+}
+
+// FilterStripControl removes control characters except tab, line feed and carriage return.
+func FilterStripControl() DescribedFilter {
+	return DescribeFilter(func(value string) string {
+		return strings.Map(func(r rune) rune {
+			if unicode.IsControl(r) && r != '\t' && r != '\n' && r != '\r' {
+				return -1
+			}
+
+			return r
+		}, value)
+	}, "strip_control", nil)
+}
+
+// FilterFoldWidth maps full-width ASCII variants and the ideographic space to ASCII,
+// and half-width katakana to full-width katakana (with voiced sound marks composed).
+// This is the part of Unicode NFKC normalization that matters for forms. e.g. "１２３" passes RuleInt after it.
+// Other compatibility characters and combining sequences are left as they are. Use FilterNFKC for whole of NFKC.
+func FilterFoldWidth() DescribedFilter {
+	return DescribeFilter(foldWidth, "fold_width", nil)
+}
+
+// Half-width katakana (U+FF61 - U+FF9F) to full-width.
+var halfWidthKana = []rune("。「」、・ヲァィゥェォャュョッーアイウエオカキクケコサシスセソタチツテトナニヌネノハヒフヘホマミムメモヤユヨラリルレロワン゛゜")
+
+func foldWidth(value string) string {
+	var b strings.Builder
+
+	for _, r := range value {
+		switch {
+		case r == '　':
+			r = ' '
+		case 0xFF01 <= r && r <= 0xFF5E:
+			r -= 0xFF01 - 0x21
+		case 0xFF61 <= r && r <= 0xFF9F:
+			r = halfWidthKana[r-0xFF61]
+
+			if composed, ok := composeKana(b.String(), r); ok {
+				s := b.String()
+				_, size := utf8.DecodeLastRuneInString(s)
+				b.Reset()
+				b.WriteString(s[:len(s)-size])
+				r = composed
+			}
+		}
+
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// composeKana composes the last katakana of s and the voiced sound mark (゛) or the semi-voiced sound mark (゜).
+func composeKana(s string, mark rune) (rune, bool) {
+	last, _ := utf8.DecodeLastRuneInString(s)
+
+	switch mark {
+	case '゛':
+		switch {
+		case last == 'ウ':
+			return 'ヴ', true
+		case strings.ContainsRune("カキクケコサシスセソタチツテトハヒフヘホ", last):
+			// Each voiced katakana follows the unvoiced one.
+			return last + 1, true
+		}
+	case '゜':
+		// Each semi-voiced katakana follows the voiced one. e.g. ハ, バ, パ
+		if 'ハ' <= last && last <= 'ホ' && (last-'ハ')%3 == 0 {
+			return last + 2, true
+		}
+	}
+
+	return 0, false
+}
+
+// FilterNFC normalizes the value to Unicode NFC. e.g. "e" + U+0301 (combining acute accent) -> "é"
+func FilterNFC() DescribedFilter {
+	return DescribeFilter(norm.NFC.String, "nfc", nil)
+}
+
+// FilterNFKC normalizes the value to Unicode NFKC. It also maps compatibility characters. e.g. "１２３" -> "123", "ｶﾞ" -> "ガ", "㌔" -> "キロ"
+func FilterNFKC() DescribedFilter {
+	return DescribeFilter(norm.NFKC.String, "nfkc", nil)
+}
+
+// FilterStripTags removes HTML tags and comments. Entities like `&amp;` are left as they are.
+// `<` that doesn't start a tag is kept. e.g. "a < b"
+func FilterStripTags() DescribedFilter {
+	return DescribeFilter(stripTags, "strip_tags", nil)
+}
+
+func stripTags(value string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(value); {
+		c := value[i]
+
+		if c != '<' || i+1 >= len(value) || !isTagStart(value[i+1]) {
+			b.WriteByte(c)
+			i++
+			continue
+		}
+
+		if strings.HasPrefix(value[i:], "<!--") {
+			end := strings.Index(value[i+4:], "-->")
+
+			if end < 0 {
+				break
+			}
+
+			i += 4 + end + 3
+			continue
+		}
+
+		// Skip to the end of the tag. `>` in quoted attribute values doesn't end it.
+		var quote byte
+
+		for i++; i < len(value); i++ {
+			c := value[i]
+
+			if quote != 0 {
+				if c == quote {
+					quote = 0
+				}
+			} else if c == '"' || c == '\'' {
+				quote = c
+			} else if c == '>' {
+				i++
+				break
+			}
+		}
+	}
+
+	return b.String()
+}
+
+func isTagStart(c byte) bool {
+	return c == '/' || c == '!' || c == '?' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+// FilterTruncate cuts the value to at most n characters.
+func FilterTruncate(n int) DescribedFilter {
+	return DescribeFilter(func(value string) string {
+		i := 0
+
+		for j := range value {
+			if i == n {
+				return value[:j]
+			}
+
+			i++
+		}
+
+		return value
+	}, "truncate", map[string]interface{}{"max": n})
+}
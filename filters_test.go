@@ -0,0 +1,113 @@
+package formspec
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// -----------------------------------------------------------------------------
+// Test built-in filters
+// -----------------------------------------------------------------------------
+
+func TestFilters(t *testing.T) {
+	examples := []struct {
+		filter   DescribedFilter
+		input    string
+		expected string
+	}{
+		{FilterTrim(), " \ttoqoz\n", "toqoz"},
+		{FilterTrimClass("P", "White_Space"), "!? toqoz. ", "toqoz"},
+		{FilterTrimClass("Zs"), "　toqoz ", "toqoz"},
+		{FilterLower(), "ToQoz", "toqoz"},
+		{FilterUpper(), "ToQoz", "TOQOZ"},
+		{FilterCollapseSpace(), " to \t\n qoz ", " to qoz "},
+		{FilterStripControl(), "to\x00qo\x7fz\r\n\t", "toqoz\r\n\t"},
+		{FilterFoldWidth(), "１２３　ＡＢＣ", "123 ABC"},
+		{FilterFoldWidth(), "ｶﾞｷﾞﾊﾟﾋﾞｳﾞｱ", "ガギパビヴア"},
+		{FilterFoldWidth(), "ﾞｶﾞ", "゛ガ"},
+		{FilterNFC(), "e\u0301", "é"},
+		{FilterNFC(), "ｶﾞ", "ｶﾞ"},
+		{FilterNFKC(), "１２３　ＡＢＣ", "123 ABC"},
+		{FilterNFKC(), "ｶﾞｷﾞﾊﾟﾋﾞｳﾞｱ", "ガギパビヴア"},
+		{FilterNFKC(), "㌔ﬁ", "キロfi"},
+		{FilterStripTags(), `<p class="a>b">Hello, <b>world</b>!</p><!-- x -->`, "Hello, world!"},
+		{FilterStripTags(), "a < b <3", "a < b <3"},
+		{FilterStripTags(), "a <!-- unterminated", "a "},
+		{FilterTruncate(3), "トキオ東京", "トキオ"},
+		{FilterTruncate(10), "toqoz", "toqoz"},
+	}
+
+	for _, example := range examples {
+		if got := example.filter.Func(example.input); got != example.expected {
+			t.Errorf("Test %s: When `%s` is given, expected result is `%s`. But got `%s`.", example.filter.Meta.Name, example.input, example.expected, got)
+		}
+	}
+}
+
+func TestFilterFoldWidth_RuleInt(t *testing.T) {
+	s := New()
+	s.Rule("age", RuleInt()).Filter(FilterFoldWidth())
+
+	if r := s.Validate(newDummyform().Set("age", "２０")); !r.Ok {
+		t.Errorf("validation error is not expected, but got %v", r.Errors)
+	}
+}
+
+func TestFilterTrimClass_Panic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for the unknown class")
+		}
+	}()
+
+	FilterTrimClass("Unknown")
+}
+
+func TestFilterMetas(t *testing.T) {
+	r := New().Rule("name", RuleRequired()).Filter(FilterTrim()).Filter(FilterTruncate(3)).Filter(strings.ToLower)
+
+	expected := []*RuleMeta{
+		{Name: "trim"},
+		{Name: "truncate", Params: map[string]interface{}{"max": 3}},
+		nil,
+	}
+
+	if metas := r.FilterMetas(); !reflect.DeepEqual(metas, expected) {
+		t.Errorf("expected metas %+v, but got %+v", expected, metas)
+	}
+
+	// Filters set to FilterFuncs directly have no RuleMeta.
+	r.FilterFuncs = append(r.FilterFuncs, strings.TrimSpace)
+	r.Filter(FilterLower())
+
+	expected = append(expected, nil, &RuleMeta{Name: "lower"})
+
+	if metas := r.FilterMetas(); !reflect.DeepEqual(metas, expected) {
+		t.Errorf("expected metas %+v, but got %+v", expected, metas)
+	}
+}
+
+func TestParseFilters(t *testing.T) {
+	filterFuncs, err := DefaultRegistry.ParseFilters("nfkc|trim|collapsespace|trimclass:P|truncate:5")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v := "  Ｈｅｌｌｏ,   world! "
+
+	for _, filterFunc := range filterFuncs {
+		v = filterFunc.Func(v)
+	}
+
+	if v != "Hello" {
+		t.Errorf("expected `Hello`, but got `%s`", v)
+	}
+
+	for _, s := range []string{"unknown", "truncate", "truncate:x", "trim:1", "trimclass", "trimclass:Unknown", "nfc:1"} {
+		if _, err := DefaultRegistry.ParseFilters(s); err == nil {
+			t.Errorf("expected error for `%s`, but got nil", s)
+		}
+	}
+}
@@ -1,13 +1,26 @@
 package formspec
 
 import (
-	"errors"
+	"context"
 	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
 )
 
 type Result struct {
 	Ok     bool     `json:"-"`
 	Errors []*Error `json:"errors"`
+	// Values are filtered values of the fields in the spec. Use them instead of raw values of the form,
+	// so that the values that are stored are the values that are validated.
+	Values Values `json:"-"`
+}
+
+// Value returns the filtered value of the field.
+func (r *Result) Value(field string) string {
+	return r.Values.FormValue(field)
 }
 
 func NewOkResult() *Result {
@@ -27,6 +40,11 @@ func NewNgResult() *Result {
 type Error struct {
 	Field   string `json:"field"`
 	Message string `json:"message"`
+	// Code is a stable machine-readable code of the error. e.g. "required", "max_len"
+	// It is "invalid" for errors returned from rule funcs that are neither *RuleError nor described.
+	Code string `json:"code,omitempty"`
+	// Params are parameters of the rule that is failed. e.g. {"max": 20}
+	Params map[string]interface{} `json:"params,omitempty"`
 }
 
 func NewError(field, message string) *Error {
@@ -37,6 +55,63 @@ func (e *Error) Error() string {
 	return e.Message
 }
 
+// RuleError is an error that has a stable code and params. All built-in rules return it.
+// Your rule funcs can return it too, so that clients can handle errors without matching messages.
+type RuleError struct {
+	Code    string
+	Params  map[string]interface{}
+	Message string
+
+	// If refFormat is not empty, the message is built by putting labels of refFields (joined by ", ") into %s of it.
+	refFormat string
+	refFields []string
+	// item is true when the error is for an item of multiple values. It has "index" param. See RuleEach.
+	item bool
+}
+
+func NewRuleError(code string, params map[string]interface{}, message string) *RuleError {
+	return &RuleError{Code: code, Params: params, Message: message}
+}
+
+func (e *RuleError) Error() string {
+	return e.Message
+}
+
+// ruleErrorf returns *RuleError that has the message formatted by the format and args.
+func ruleErrorf(code string, params map[string]interface{}, format string, a ...interface{}) *RuleError {
+	if len(a) > 0 {
+		format = fmt.Sprintf(format, a...)
+	}
+
+	return NewRuleError(code, params, format)
+}
+
+// fieldRefError returns *RuleError that refers other fields in the message. See RuleError.refFormat.
+func fieldRefError(code string, params map[string]interface{}, format string, fields []string) *RuleError {
+	e := &RuleError{Code: code, Params: params, refFormat: format, refFields: fields}
+	e.Message = e.message(func(field string) string { return field })
+	return e
+}
+
+func (e *RuleError) message(labelOf func(string) string) string {
+	if e.refFormat == "" {
+		return e.Message
+	}
+
+	return fmt.Sprintf(e.refFormat, e.refLabels(labelOf))
+}
+
+// refLabels returns labels of refFields joined by ", ".
+func (e *RuleError) refLabels(labelOf func(string) string) string {
+	labels := make([]string, len(e.refFields))
+
+	for i, field := range e.refFields {
+		labels[i] = labelOf(field)
+	}
+
+	return strings.Join(labels, ", ")
+}
+
 // ----------------------------------------------------------------------------
 // Formspec
 // ----------------------------------------------------------------------------
@@ -45,42 +120,388 @@ type Form interface {
 	FormValue(string) string
 }
 
+// MultiForm is a Form that can return every value of a repeated parameter like `tags=a&tags=b`.
+// *http.Request is treated as MultiForm by reading r.Form, and url.Values can be used through Values.
+type MultiForm interface {
+	Form
+	FormValues(string) []string
+}
+
+// Values adapts url.Values to MultiForm.
+type Values url.Values
+
+func (v Values) FormValue(key string) string {
+	return url.Values(v).Get(key)
+}
+
+func (v Values) FormValues(key string) []string {
+	return v[key]
+}
+
+// typedForm is a Form that knows types of its values. See JSONForm.
+type typedForm interface {
+	typeError(field string, multi bool) *RuleError
+}
+
+// FileForm is a Form that can return uploaded files. *http.Request satisfies it.
+type FileForm interface {
+	Form
+	FormFile(string) (multipart.File, *multipart.FileHeader, error)
+}
+
+// formFile returns the header of the file uploaded as the field.
+// It returns nil when no file is uploaded or the form can't have files.
+func formFile(f Form, field string) *multipart.FileHeader {
+	ff, ok := f.(FileForm)
+
+	if !ok {
+		return nil
+	}
+
+	file, header, err := ff.FormFile(field)
+
+	if err != nil {
+		return nil
+	}
+
+	file.Close()
+	return header
+}
+
+// formValues returns all values of the field in the form.
+// If the form can only return single value, it returns the value as a single item (or no items when it is blank).
+func formValues(f Form, field string) []string {
+	switch f := f.(type) {
+	case MultiForm:
+		return f.FormValues(field)
+	case *http.Request:
+		// FormValue parses the request body and populates Request.Form.
+		f.FormValue(field)
+		return f.Form[field]
+	}
+
+	if v := f.FormValue(field); v != "" {
+		return []string{v}
+	}
+
+	return nil
+}
+
 func New() *Formspec {
 	return &Formspec{}
 }
 
 type Formspec struct {
 	Rules []*Rule
+	// Catalog has message templates for ValidateLocale. If it is nil, DefaultCatalog is used.
+	Catalog Catalog
+	// Human labels of fields that are used in error messages instead of field names.
+	labels map[string]string
+	// Concurrency is the max number of rules that are called concurrently. Rules are called one by one when it is 0 or 1.
+	// Errors are reported in declared order of rules regardless of it. Forms must be safe to read concurrently.
+	Concurrency int
+	// Bail stops validating a field at its first failing rule. See BailField to set it for some fields.
+	Bail bool
+	// FailFast stops validating the form at the first failing rule. The Result has only the error.
+	FailFast bool
+	// Strict rejects fields that are not in the spec. See Formspec.Allow.
+	Strict bool
+	// Partial validates only the fields that are in the form, so that missing fields mean "unchanged" (e.g. PATCH).
+	// Fields that are in the form are validated even if they are blank. Result.Values has only them.
+	Partial bool
+	// The scenario of the spec. See Formspec.Scenario.
+	scenario string
+	// Fields that stop at the first failing rule.
+	bailFields map[string]bool
+	// Fields that are allowed in strict mode though they have no rules.
+	allowed map[string]bool
+	// Specs mounted under prefixes.
+	mounts []*Mount
+	// Templates of error messages for error codes.
+	templates map[string]*template.Template
 }
 
-func (f *Formspec) Rule(field string, ruleFunc RuleFunc) *Rule {
-	rule := &Rule{Field: field, RuleFunc: ruleFunc}
+// Rule adds a rule of the field. ruleFunc is RuleFunc, or DescribedRule that is returned from built-in rules and Describe.
+// It panics for values of other types. e.g. DescribedMultiRule (Use Formspec.MultiRule)
+func (f *Formspec) Rule(field string, ruleFunc interface{}) *Rule {
+	rule := &Rule{Field: field}
+	rule.RuleFunc, rule.meta = ruleFuncOf(ruleFunc)
 	f.Rules = append(f.Rules, rule)
 	return rule
 }
 
+// MultiRule adds a rule that receives all values of the field. multiRuleFunc is MultiRuleFunc or DescribedMultiRule.
+// It panics for values of other types.
+func (f *Formspec) MultiRule(field string, multiRuleFunc interface{}) *Rule {
+	rule := &Rule{Field: field}
+	rule.MultiRuleFunc, rule.meta = multiRuleFuncOf(multiRuleFunc)
+	f.Rules = append(f.Rules, rule)
+	return rule
+}
+
+// FileRule adds a rule that receives the file uploaded as the field. fileRuleFunc is FileRuleFunc or DescribedFileRule.
+// It panics for values of other types.
+func (f *Formspec) FileRule(field string, fileRuleFunc interface{}) *Rule {
+	rule := &Rule{Field: field}
+	rule.FileRuleFunc, rule.meta = fileRuleFuncOf(fileRuleFunc)
+	f.Rules = append(f.Rules, rule)
+	return rule
+}
+
+// Label sets the human label of the field. Error messages use it instead of the field name.
+//
+//	s.Label("password_confirmation", "Password confirmation")
+func (f *Formspec) Label(field, label string) *Formspec {
+	if f.labels == nil {
+		f.labels = map[string]string{}
+	}
+
+	f.labels[field] = label
+	return f
+}
+
+// LabelOf returns the label of the field. It returns the field name when no label is set.
+func (f *Formspec) LabelOf(field string) string {
+	if l, ok := f.labels[field]; ok {
+		return l
+	}
+
+	return field
+}
+
+// Validate validates the form.
+// Rules that failed to check the value (See ContextRuleFunc) are reported as errors that have code "internal".
+// Use ValidateContext to handle them as server errors.
 func (f *Formspec) Validate(form Form) *Result {
+	return f.validateAll(form, f.messenger(""))
+}
+
+// validateAll validates the form with background context, and reports InternalError as *Error.
+func (f *Formspec) validateAll(form Form, m *messenger) *Result {
+	r, _ := f.check(context.Background(), form, m, false)
+	return r
+}
+
+func (f *Formspec) validate(ctx context.Context, form Form, m *messenger) (*Result, error) {
+	r, ierr := f.check(ctx, form, m, true)
+
+	if ierr != nil {
+		return nil, ierr
+	}
+
+	return r, nil
+}
+
+// check validates the form by rules, mounted specs (See Formspec.Mount) and strict mode in this order.
+// If abort is true, it stops at the first InternalError and returns it.
+// Otherwise InternalError is reported as *Error that has code "internal".
+func (f *Formspec) check(ctx context.Context, form Form, m *messenger, abort bool) (*Result, *InternalError) {
+	a := f.active(form)
+	outcomes, ierr := a.run(ctx, form, m, abort)
+
+	if ierr != nil && abort {
+		return nil, ierr
+	}
+
 	r := NewOkResult()
+	r.Values = a.values(form)
 
-	for _, rule := range f.Rules {
-		err := rule.Call(form)
+	typeErrors := map[string]bool{}
+
+	for i, o := range outcomes {
+		err := o.err
+
+		if o.ierr != nil {
+			err = m.internalError(a.Rules[i].Field)
+		}
 
-		if err != nil {
-			r.Ok = false
-			r.Errors = append(r.Errors, NewError(rule.Field, err.Error()))
+		if err == nil {
+			continue
 		}
+
+		// Every rule of the field fails by the type of the value. See JSONForm.
+		if err.Code == "json_type" {
+			if typeErrors[err.Field] {
+				continue
+			}
+
+			typeErrors[err.Field] = true
+		}
+
+		r.Errors = append(r.Errors, err)
 	}
 
-	return r
+	for _, mount := range f.mounts {
+		if f.FailFast && len(r.Errors) > 0 {
+			break
+		}
+
+		if ierr := mount.check(ctx, form, m, abort, f.Partial, r); ierr != nil {
+			return nil, ierr
+		}
+	}
+
+	if f.Strict && !(f.FailFast && len(r.Errors) > 0) {
+		r.Errors = append(r.Errors, f.unknownErrors(form, m)...)
+	}
+
+	if f.FailFast && len(r.Errors) > 1 {
+		r.Errors = r.Errors[:1]
+	}
+
+	r.Ok = len(r.Errors) == 0
+	return r, nil
+}
+
+// outcome is the result of calling a rule.
+type outcome struct {
+	err  *Error
+	ierr *InternalError
+}
+
+func (o outcome) failed() bool {
+	return o.err != nil || o.ierr != nil
+}
+
+// run calls rules and returns their outcomes in declared order, and the first InternalError.
+// If abort is true, it stops calling rules at the InternalError. Rules that are not called have zero outcomes.
+func (f *Formspec) run(ctx context.Context, form Form, m *messenger, abort bool) ([]outcome, *InternalError) {
+	var (
+		outcomes []outcome
+		first    *InternalError
+	)
+
+	if f.Concurrency > 1 {
+		outcomes, first = f.runConcurrently(ctx, form, m, abort)
+	} else {
+		outcomes, first = f.runSequentially(ctx, form, m, abort)
+	}
+
+	if f.FailFast {
+		// Concurrent rules that are declared after the first failure may fail before the stop. Only the first one in declared order is kept.
+		for i := range outcomes {
+			if outcomes[i].failed() {
+				clear(outcomes[i+1:])
+				break
+			}
+		}
+	}
+
+	return outcomes, first
+}
+
+func (f *Formspec) runSequentially(ctx context.Context, form Form, m *messenger, abort bool) ([]outcome, *InternalError) {
+	outcomes := make([]outcome, len(f.Rules))
+	failed := map[string]bool{}
+
+	var first *InternalError
+
+	for i, rule := range f.Rules {
+		if failed[rule.Field] && f.bails(rule.Field) {
+			continue
+		}
+
+		if err := ctx.Err(); err != nil {
+			outcomes[i].ierr = &InternalError{Field: rule.Field, Err: err}
+		} else {
+			outcomes[i].err, outcomes[i].ierr = rule.call(ctx, form, m)
+		}
+
+		if outcomes[i].ierr != nil && first == nil {
+			first = outcomes[i].ierr
+
+			if abort {
+				break
+			}
+		}
+
+		if outcomes[i].failed() {
+			failed[rule.Field] = true
+
+			if f.FailFast {
+				break
+			}
+		}
+	}
+
+	return outcomes, first
+}
+
+// values returns filtered values of the fields in the form.
+// Fields of multi-value rules have all values, and other fields have the first value. Files and groups are not included.
+func (f *Formspec) values(form Form) Values {
+	values := Values{}
+
+	for _, field := range f.fields() {
+		var multi, single bool
+
+		for _, rule := range f.Rules {
+			if rule.Field != field || rule.FileRuleFunc != nil || rule.GroupFields() != nil {
+				continue
+			}
+
+			if rule.MultiRuleFunc != nil {
+				multi = true
+			} else {
+				single = true
+			}
+		}
+
+		switch {
+		case multi:
+			for _, v := range formValues(form, field) {
+				values[field] = append(values[field], f.filterValue(field, v))
+			}
+		case single:
+			values[field] = []string{f.filterValue(field, form.FormValue(field))}
+		}
+	}
+
+	return values
+}
+
+// filterValue applies FilterFuncs of all rules for the field in declared order.
+func (f *Formspec) filterValue(field, value string) string {
+	for _, rule := range f.Rules {
+		if rule.Field == field {
+			value = rule.filter(value)
+		}
+	}
+
+	return value
 }
 
 func (f *Formspec) Clone() *Formspec {
-	clone := &Formspec{}
+	clone := &Formspec{Catalog: f.Catalog, Concurrency: f.Concurrency, Bail: f.Bail, FailFast: f.FailFast, Strict: f.Strict, Partial: f.Partial, scenario: f.scenario}
 
 	for _, rule := range f.Rules {
 		clone.Rules = append(clone.Rules, rule.clone())
 	}
 
+	for field, label := range f.labels {
+		clone.Label(field, label)
+	}
+
+	for field := range f.bailFields {
+		clone.BailField(field)
+	}
+
+	for field := range f.allowed {
+		clone.Allow(field)
+	}
+
+	for _, mount := range f.mounts {
+		clone.mounts = append(clone.mounts, mount.clone())
+	}
+
+	for code, t := range f.templates {
+		if clone.templates == nil {
+			clone.templates = map[string]*template.Template{}
+		}
+
+		clone.templates[code] = t
+	}
+
 	return clone
 }
 
@@ -91,12 +512,33 @@ func (f *Formspec) Clone() *Formspec {
 type FilterFunc func(string) string
 type RuleFunc func(value string, f Form) error
 
-type Rule struct {
-	Field       string
-	RuleFunc    RuleFunc
-	FilterFuncs []FilterFunc
-	allowBlank  bool
+// MultiRuleFunc is a RuleFunc for fields that have multiple values. e.g. checkboxes, multi-selects.
+type MultiRuleFunc func(values []string, f Form) error
 
+// FileRuleFunc is a RuleFunc for uploaded files. file is nil when no file is uploaded.
+type FileRuleFunc func(file *multipart.FileHeader, f Form) error
+
+type Rule struct {
+	Field           string
+	RuleFunc        RuleFunc
+	ContextRuleFunc ContextRuleFunc
+	MultiRuleFunc   MultiRuleFunc
+	FileRuleFunc    FileRuleFunc
+	FilterFuncs     []FilterFunc
+	allowBlank      bool
+	// RuleMeta of the rule func. See Rule.Meta.
+	meta *RuleMeta
+	// RuleMeta of FilterFuncs. See Rule.FilterMetas.
+	filterMetas []*RuleMeta
+	// The rule is applied only when all of them match the form.
+	conditions []*Condition
+	// The rule is applied only in them. See Rule.On.
+	scenarios []string
+
+	// This is used prior to the label of the field set by Formspec.Label.
+	label string
+	// This is used prior to Rule.fullMessage.
+	template *template.Template
 	// This is used prior to Rule.message.
 	fullMessage string
 	// This is used prior to error message that is returned from Rule.RuleFunc.
@@ -122,50 +564,179 @@ func (r *Rule) Message(m string) *Rule {
 	return r
 }
 
-func (r *Rule) Filter(filterFunc FilterFunc) *Rule {
-	r.FilterFuncs = append(r.FilterFuncs, filterFunc)
+// Filter adds the filter of the rule. filterFunc is FilterFunc, or DescribedFilter that is returned from built-in filters and DescribeFilter.
+// It panics for values of other types.
+func (r *Rule) Filter(filterFunc interface{}) *Rule {
+	fn, meta := filterFuncOf(filterFunc)
+	// RuleMeta is kept in same index as the func. Filters set to FilterFuncs directly have no RuleMeta.
+	r.filterMetas = append(r.FilterMetas(), meta)
+	r.FilterFuncs = append(r.FilterFuncs, fn)
 	return r
 }
 
+// Call calls the rule func with background context. It returns *Error, or *InternalError when the rule failed to check the value.
 func (r *Rule) Call(f Form) error {
-	v := f.FormValue(r.Field)
+	err, ierr := r.call(context.Background(), f, nil)
 
-	// Filter value
-	if len(r.FilterFuncs) > 0 {
-		for _, filterFunc := range r.FilterFuncs {
-			v = filterFunc(v)
+	if ierr != nil {
+		return ierr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// call calls the rule func. m builds error messages. If it is nil, field names are used as labels.
+// The second result is not nil when the rule failed to check the value.
+func (r *Rule) call(ctx context.Context, f Form, m *messenger) (*Error, *InternalError) {
+	if !r.applicable(f) {
+		return nil, nil
+	}
+
+	if tf, ok := f.(typedForm); ok && r.FileRuleFunc == nil {
+		if err := tf.typeError(r.Field, r.MultiRuleFunc != nil); err != nil {
+			return r.error(err, m), nil
 		}
 	}
 
+	var err error
+
+	switch {
+	case r.MultiRuleFunc != nil:
+		err = r.callMulti(f)
+	case r.FileRuleFunc != nil:
+		err = r.callFile(f)
+	default:
+		err = r.callValue(ctx, f)
+	}
+
+	if ierr := internalError(r.Field, err); ierr != nil {
+		return nil, ierr
+	}
+
+	return r.error(err, m), nil
+}
+
+func (r *Rule) callValue(ctx context.Context, f Form) error {
+	v := r.filter(f.FormValue(r.Field))
+
 	// If rule.allowblank is true, all rule returns no error when value is blank.
-	if v == "" && r.allowBlank {
+	// Group rules are not skipped, because their field is the name of the group that is usually blank. See Formspec.AtLeastOneOf.
+	if v == "" && r.allowBlank && r.GroupFields() == nil {
 		return nil
 	}
 
-	err := r.RuleFunc(v, f)
+	if r.ContextRuleFunc != nil {
+		return r.ContextRuleFunc(ctx, v, f)
+	}
 
-	if err != nil {
-		if r.fullMessage != "" {
-			return errors.New(r.fullMessage)
-		}
+	return r.RuleFunc(v, f)
+}
+
+func (r *Rule) callMulti(f Form) error {
+	values := formValues(f, r.Field)
+	filtered := make([]string, len(values))
+	blank := true
+
+	for i, v := range values {
+		filtered[i] = r.filter(v)
 
-		if r.message != "" {
-			return fmt.Errorf("%s %s", r.Field, r.message)
+		if filtered[i] != "" {
+			blank = false
 		}
+	}
 
-		return fmt.Errorf("%s %s", r.Field, err.Error())
+	// If rule.allowblank is true, all rule returns no error when all values are blank.
+	if blank && r.allowBlank {
+		return nil
 	}
 
-	return nil
+	return r.MultiRuleFunc(filtered, f)
+}
+
+func (r *Rule) callFile(f Form) error {
+	file := formFile(f, r.Field)
+
+	// If rule.allowblank is true, all rule returns no error when no file is uploaded.
+	if file == nil && r.allowBlank {
+		return nil
+	}
+
+	return r.FileRuleFunc(file, f)
+}
+
+func (r *Rule) filter(v string) string {
+	for _, filterFunc := range r.FilterFuncs {
+		v = filterFunc(v)
+	}
+
+	return v
+}
+
+// error converts the error returned from rule funcs to *Error.
+// The message is overridden by Rule.fullMessage or Rule.message, but the code and params are kept.
+func (r *Rule) error(err error, m *messenger) *Error {
+	if err == nil {
+		return nil
+	}
+
+	e := &Error{Field: r.Field, Code: "invalid"}
+	message := err.Error()
+	label := m.label(r.Field)
+
+	if r.label != "" {
+		label = r.label
+	}
+
+	rerr, ok := err.(*RuleError)
+
+	if ok {
+		e.Code, e.Params = rerr.Code, rerr.Params
+		message = rerr.message(m.label)
+	} else if meta := r.Meta(); meta != nil {
+		e.Code, e.Params = meta.Name, meta.Params
+	}
+
+	e.Message = fmt.Sprintf("%s %s", label, message)
+
+	switch {
+	case r.template != nil:
+		if s, ok := executeTemplate(r.template, m.data(rerr, e.Params, label, message)); ok {
+			e.Message = s
+		}
+	case r.fullMessage != "":
+		e.Message = r.fullMessage
+	case r.message != "":
+		e.Message = fmt.Sprintf("%s %s", label, r.message)
+	default:
+		if s, ok := m.localize(rerr, label, message); ok {
+			e.Message = s
+		}
+	}
+
+	return e
 }
 
+// clone returns the copy of the rule. Slices that methods of the rule append to are copied, so that clones don't change each other.
 func (r *Rule) clone() *Rule {
 	return &Rule{
-		Field:       r.Field,
-		RuleFunc:    r.RuleFunc,
-		FilterFuncs: r.FilterFuncs,
-		allowBlank:  r.allowBlank,
-		message:     r.message,
-		fullMessage: r.fullMessage,
+		Field:           r.Field,
+		RuleFunc:        r.RuleFunc,
+		ContextRuleFunc: r.ContextRuleFunc,
+		MultiRuleFunc:   r.MultiRuleFunc,
+		FileRuleFunc:    r.FileRuleFunc,
+		FilterFuncs:     r.FilterFuncs,
+		allowBlank:      r.allowBlank,
+		meta:            r.meta,
+		filterMetas:     r.filterMetas,
+		conditions:      append([]*Condition(nil), r.conditions...),
+		scenarios:       append([]string(nil), r.scenarios...),
+		label:           r.label,
+		template:        r.template,
+		message:         r.message,
+		fullMessage:     r.fullMessage,
 	}
 }
@@ -1,7 +1,12 @@
 package formspec
 
 import (
+	"encoding/json"
 	"errors"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -118,3 +123,121 @@ func TestClone(t *testing.T) {
 		t.Errorf("validation error is not expected, but got it.")
 	}
 }
+
+func TestMultiRule(t *testing.T) {
+	s := New()
+	s.MultiRule("tags", RuleMaxItems(2))
+
+	// Test
+	//   when 3 tags are given via url.Values
+	//     formspec returns error `tags must have at most 2 items.`
+	r := s.Validate(Values(url.Values{"tags": {"a", "b", "c"}}))
+
+	if r.Ok {
+		t.Errorf("validation error is expected, but not got it.")
+		return
+	}
+
+	if r.Errors[0].Message != "tags must have at most 2 items." {
+		t.Errorf("expected error `tags must have at most 2 items.`, but got `%s`", r.Errors[0].Message)
+	}
+
+	// Test
+	//   when 3 tags are given via *http.Request
+	//     formspec returns error
+	req, _ := http.NewRequest("POST", "/", strings.NewReader("tags=a&tags=b&tags=c"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	if r := s.Validate(req); r.Ok {
+		t.Errorf("validation error is expected, but not got it.")
+	}
+
+	// Test
+	//   when the form can only return single value
+	//     formspec treats it as one item
+	if r := s.Validate(newDummyform().Set("tags", "a")); !r.Ok {
+		t.Errorf("validation error is not expected, but got it.")
+	}
+}
+
+func TestMultiRule_AllowBlank(t *testing.T) {
+	s := New()
+	s.MultiRule("tags", RuleMinItems(2)).AllowBlank()
+
+	if r := s.Validate(Values(url.Values{})); !r.Ok {
+		t.Errorf("validation error is not expected, but got it.")
+	}
+
+	if r := s.Validate(Values(url.Values{"tags": {"a"}})); r.Ok {
+		t.Errorf("validation error is expected, but not got it.")
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	s := New()
+	s.Rule("name", RuleRequired())
+	s.Rule("nick", RuleMaxLen(3)).Message("is too long.")
+	s.Rule("age", RuleIntGreaterThan(0)).FullMessage("Age must be positive.")
+	s.Rule("code", func(value string, f Form) error { return errors.New("is invalid.") })
+	s.MultiRule("ids", RuleEach(RuleInt()))
+
+	f := Values(url.Values{"nick": {"toqoz"}, "age": {"x"}, "ids": {"1", "x"}})
+	r := s.Validate(f)
+
+	j, err := json.Marshal(r)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"errors":[` +
+		`{"field":"name","message":"name is required.","code":"required"},` +
+		`{"field":"nick","message":"nick is too long.","code":"max_len","params":{"max":3}},` +
+		`{"field":"age","message":"Age must be positive.","code":"int"},` +
+		`{"field":"code","message":"code is invalid.","code":"invalid"},` +
+		`{"field":"ids","message":"ids item 1 must be integer.","code":"int","params":{"index":1}}]}`
+
+	if string(j) != expected {
+		t.Errorf("expected\n%s\nbut got\n%s", expected, j)
+	}
+
+	// Rule.Call returns *Error too.
+	if err, ok := s.Rules[0].Call(f).(*Error); !ok || err.Code != "required" {
+		t.Errorf("expected *Error that has code `required`, but got %#v", err)
+	}
+
+	if err := s.Rules[0].Call(Values(url.Values{"name": {"toqoz"}})); err != nil {
+		t.Errorf("expected nil, but got %#v", err)
+	}
+}
+
+func TestResultValues(t *testing.T) {
+	s := New()
+	s.Rule("name", RuleRequired()).Filter(strings.TrimSpace)
+	s.Rule("name", RuleMaxLen(5)).Filter(strings.ToLower)
+	s.Rule("nick", RuleMaxLen(3))
+	s.MultiRule("tags", RuleMaxItems(3)).Filter(strings.TrimSpace)
+	s.AtLeastOneOf("contact", "email", "phone")
+
+	f := Values(url.Values{"name": {" ToQoz "}, "nick": {"toqoz"}, "tags": {" a", "b "}, "email": {"a@example.com"}, "raw": {"x"}})
+	r := s.Validate(f)
+
+	// Values are returned even if validation is failed.
+	if r.Ok {
+		t.Errorf("validation error is expected, but not got it.")
+	}
+
+	expected := Values{"name": {"toqoz"}, "nick": {"toqoz"}, "tags": {"a", "b"}}
+
+	if !reflect.DeepEqual(r.Values, expected) {
+		t.Errorf("expected values %v, but got %v", expected, r.Values)
+	}
+
+	if r.Value("name") != "toqoz" {
+		t.Errorf("expected value `toqoz`, but got `%s`", r.Value("name"))
+	}
+
+	if r.Value("raw") != "" {
+		t.Errorf("expected values not in the spec to be blank, but got `%s`", r.Value("raw"))
+	}
+}
@@ -0,0 +1,5 @@
+module github.com/ToQoz/go-formspec/v2
+
+go 1.21
+
+require golang.org/x/text v0.22.0
@@ -0,0 +1,89 @@
+package formspec
+
+var (
+	// Default messages for group rules. %s is replaced with labels of the fields in the group.
+
+	RuleMessageAtLeastOneOf      = "requires at least one of %s."
+	RuleMessageExactlyOneOf      = "requires exactly one of %s."
+	RuleMessageMutuallyExclusive = "allows only one of %s."
+)
+
+// funcs that return RuleFunc checking a group of fields.
+// They ignore the value of the rule's field, so the rule's field can be the name of the group or one of the fields.
+
+func RuleAtLeastOneOf(fields ...string) DescribedRule {
+	return Describe(func(_ string, f Form) error {
+		if countPresent(f, fields) < 1 {
+			return fieldRefError("at_least_one_of", map[string]interface{}{"fields": fields}, RuleMessageAtLeastOneOf, fields)
+		}
+
+		return nil
+	}, "at_least_one_of", map[string]interface{}{"fields": fields})
+}
+
+func RuleExactlyOneOf(fields ...string) DescribedRule {
+	return Describe(func(_ string, f Form) error {
+		if countPresent(f, fields) != 1 {
+			return fieldRefError("exactly_one_of", map[string]interface{}{"fields": fields}, RuleMessageExactlyOneOf, fields)
+		}
+
+		return nil
+	}, "exactly_one_of", map[string]interface{}{"fields": fields})
+}
+
+// RuleMutuallyExclusive checks at most one of the fields is present.
+func RuleMutuallyExclusive(fields ...string) DescribedRule {
+	return Describe(func(_ string, f Form) error {
+		if countPresent(f, fields) > 1 {
+			return fieldRefError("mutually_exclusive", map[string]interface{}{"fields": fields}, RuleMessageMutuallyExclusive, fields)
+		}
+
+		return nil
+	}, "mutually_exclusive", map[string]interface{}{"fields": fields})
+}
+
+func countPresent(f Form, fields []string) int {
+	n := 0
+
+	for _, field := range fields {
+		if f.FormValue(field) != "" {
+			n++
+		}
+	}
+
+	return n
+}
+
+// AtLeastOneOf adds the group rule that at least one of the fields is present.
+// Errors are reported as the name, that is a name of the group or one of the fields.
+//
+//	s.AtLeastOneOf("contact", "email", "phone")
+func (f *Formspec) AtLeastOneOf(name string, fields ...string) *Rule {
+	return f.Rule(name, RuleAtLeastOneOf(fields...))
+}
+
+// ExactlyOneOf adds the group rule that exactly one of the fields is present. See AtLeastOneOf.
+func (f *Formspec) ExactlyOneOf(name string, fields ...string) *Rule {
+	return f.Rule(name, RuleExactlyOneOf(fields...))
+}
+
+// MutuallyExclusive adds the group rule that at most one of the fields is present. See AtLeastOneOf.
+//
+//	s.MutuallyExclusive("coupon_code", "coupon_code", "gift_card")
+func (f *Formspec) MutuallyExclusive(name string, fields ...string) *Rule {
+	return f.Rule(name, RuleMutuallyExclusive(fields...))
+}
+
+// GroupFields returns the fields of the group if the rule is a group rule. Otherwise it returns nil.
+func (r *Rule) GroupFields() []string {
+	if meta := r.Meta(); meta != nil && isGroupRule(meta.Name) {
+		fields, _ := meta.Params["fields"].([]string)
+		return fields
+	}
+
+	return nil
+}
+
+func isGroupRule(name string) bool {
+	return name == "at_least_one_of" || name == "exactly_one_of" || name == "mutually_exclusive"
+}
@@ -0,0 +1,112 @@
+package formspec
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type groupTestExample struct {
+	form     *dummyForm
+	expected bool
+}
+
+func testGroupRule(t *testing.T, name string, s *Formspec, examples []groupTestExample) {
+	for _, example := range examples {
+		if r := s.Validate(example.form); r.Ok != example.expected {
+			t.Errorf("Test %s: When %v is given, expected result is (_, %v). But got (_, %v).", name, example.form.form, example.expected, r.Ok)
+		}
+	}
+}
+
+func TestAtLeastOneOf(t *testing.T) {
+	s := New()
+	s.AtLeastOneOf("contact", "email", "phone")
+
+	testGroupRule(t, "AtLeastOneOf", s, []groupTestExample{
+		{newDummyform().Set("email", "a@example.com"), true},
+		{newDummyform().Set("email", "a@example.com").Set("phone", "000"), true},
+		{newDummyform().Set("email", ""), false},
+		{newDummyform(), false},
+	})
+
+	r := s.Validate(newDummyform())
+
+	if r.Errors[0].Field != "contact" || r.Errors[0].Message != "contact requires at least one of email, phone." {
+		t.Errorf("expected error `contact requires at least one of email, phone.` in contact, but got %+v", r.Errors[0])
+	}
+
+	s.Label("email", "Email").Label("phone", "Phone")
+
+	if r := s.Validate(newDummyform()); r.Errors[0].Message != "contact requires at least one of Email, Phone." {
+		t.Errorf("expected error with labels, but got `%s`", r.Errors[0].Message)
+	}
+
+	// The group name is blank, but group rules are not skipped by AllowBlank.
+	s = New()
+	s.AtLeastOneOf("contact", "email", "phone").AllowBlank()
+
+	testGroupRule(t, "AtLeastOneOf.AllowBlank", s, []groupTestExample{
+		{newDummyform().Set("phone", "000"), true},
+		{newDummyform(), false},
+	})
+}
+
+func TestExactlyOneOf(t *testing.T) {
+	s := New()
+	s.ExactlyOneOf("payment", "card", "bank")
+
+	testGroupRule(t, "ExactlyOneOf", s, []groupTestExample{
+		{newDummyform().Set("card", "1"), true},
+		{newDummyform().Set("bank", "1"), true},
+		{newDummyform().Set("card", "1").Set("bank", "1"), false},
+		{newDummyform(), false},
+	})
+}
+
+func TestMutuallyExclusive(t *testing.T) {
+	s := New()
+	s.MutuallyExclusive("coupon_code", "coupon_code", "gift_card").FullMessage("Use a coupon code or a gift card, not both.")
+
+	testGroupRule(t, "MutuallyExclusive", s, []groupTestExample{
+		{newDummyform(), true},
+		{newDummyform().Set("coupon_code", "1"), true},
+		{newDummyform().Set("coupon_code", "1").Set("gift_card", "1"), false},
+	})
+
+	r := s.Validate(newDummyform().Set("coupon_code", "1").Set("gift_card", "1"))
+
+	if r.Errors[0].Field != "coupon_code" || r.Errors[0].Message != "Use a coupon code or a gift card, not both." {
+		t.Errorf("expected error in coupon_code, but got %+v", r.Errors[0])
+	}
+}
+
+func TestGroupRule_Introspection(t *testing.T) {
+	s := New()
+	s.Rule("email", RuleMaxLen(100))
+	s.AtLeastOneOf("contact", "email", "phone")
+	s.MutuallyExclusive("coupon_code", "coupon_code", "gift_card")
+
+	if fields := s.Rules[1].GroupFields(); !reflect.DeepEqual(fields, []string{"email", "phone"}) {
+		t.Errorf("expected group fields [email phone], but got %v", fields)
+	}
+
+	if fields := s.Rules[0].GroupFields(); fields != nil {
+		t.Errorf("expected no group fields, but got %v", fields)
+	}
+
+	j, _ := json.Marshal(s.JSONSchema())
+
+	expected := `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object",` +
+		`"properties":{"email":{"type":"string","maxLength":100}},"allOf":[` +
+		`{"anyOf":[{"required":["email"]},{"required":["phone"]}]},` +
+		`{"not":{"required":["coupon_code","gift_card"]}}]}`
+
+	if string(j) != expected {
+		t.Errorf("expected\n%s\nbut got\n%s", expected, j)
+	}
+
+	if params := s.OpenAPIParameters("query"); len(params) != 1 {
+		t.Errorf("expected group names are not parameters, but got %d parameters", len(params))
+	}
+}
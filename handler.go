@@ -0,0 +1,194 @@
+package formspec
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Media types of responses written by WriteResult.
+const (
+	MediaTypeJSON    = "application/json"
+	MediaTypeProblem = "application/problem+json"
+	MediaTypeText    = "text/plain"
+)
+
+type resultKey struct{}
+
+// ResultFrom returns the Result stored in the context by Handler. It returns nil when there is no Result.
+func ResultFrom(ctx context.Context) *Result {
+	r, _ := ctx.Value(resultKey{}).(*Result)
+	return r
+}
+
+// WithResult returns the context that has the Result. See ResultFrom.
+func WithResult(ctx context.Context, r *Result) context.Context {
+	return context.WithValue(ctx, resultKey{}, r)
+}
+
+// JSONBodyLimit is the max size of JSON bodies that Handler reads.
+var JSONBodyLimit int64 = 10 << 20
+
+// Messages of errors that Handler responds when the JSON body can't be read. They have no field, because they are errors of the whole body.
+var (
+	HandlerMessageBodyTooLarge = "The request body is too large. Max is %d bytes."
+	HandlerMessageBadJSON      = "The request body is not valid JSON."
+)
+
+// Handler returns the handler that validates requests by the spec before calling next.
+// Messages are localized by Accept-Language (See ValidateRequest).
+// JSON bodies (Content-Type: application/json) are read as JSONForm, and next can read the body again.
+// Bodies that are too large or broken are responded with 413 Request Entity Too Large or 400 Bad Request by WriteResult.
+// Their errors have code "body_too_large" (with "max" param) or "bad_json".
+//
+// Invalid requests are responded with 422 Unprocessable Entity by WriteResult, and next is not called.
+// Requests that can't be validated (See ContextRuleFunc) are responded with 500 Internal Server Error by WriteResult.
+// Their errors have code "internal".
+// Otherwise next is called with the request that has the Result in its context. Use ResultFrom to take it out.
+//
+//	http.Handle("/users", formspec.Handler(signUpSpec, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+//		name := formspec.ResultFrom(r.Context()).Value("name")
+//	})))
+func Handler(f *Formspec, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var form Form = r
+
+		if mediaType, _, _ := strings.Cut(r.Header.Get("Content-Type"), ";"); strings.TrimSpace(mediaType) == MediaTypeJSON {
+			jf, err := ReadJSONForm(r, JSONBodyLimit)
+
+			switch {
+			case err == ErrBodyTooLarge:
+				writeError(w, r, http.StatusRequestEntityTooLarge, &Error{Message: fmt.Sprintf(HandlerMessageBodyTooLarge, JSONBodyLimit), Code: "body_too_large", Params: map[string]interface{}{"max": JSONBodyLimit}})
+				return
+			case err != nil:
+				writeError(w, r, http.StatusBadRequest, &Error{Message: HandlerMessageBadJSON, Code: "bad_json"})
+				return
+			}
+
+			// The body is consumed by ReadJSONForm, so it is given to next again.
+			r.Body = io.NopCloser(bytes.NewReader(jf.Bytes()))
+			form = jf
+		}
+
+		m := f.messenger(MatchLocale(r.Header.Get("Accept-Language"), f.catalog().Locales()))
+		result, ierr := f.check(r.Context(), form, m, true)
+
+		if ierr != nil {
+			writeError(w, r, http.StatusInternalServerError, m.internalError(ierr.Field))
+			return
+		}
+
+		if !result.Ok {
+			WriteResult(w, r, result, http.StatusUnprocessableEntity)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(WithResult(r.Context(), result)))
+	})
+}
+
+// writeError writes the result that has only the error by WriteResult.
+func writeError(w http.ResponseWriter, r *http.Request, status int, e *Error) {
+	result := NewOkResult()
+	result.Ok = false
+	result.Errors = []*Error{e}
+	WriteResult(w, r, result, status)
+}
+
+// WriteResult writes the result with the status in the media type that matches the Accept header of the request best.
+// It is JSON of the Result (the default), problem details (See Problem) or plain text that has an error message per line.
+func WriteResult(w http.ResponseWriter, r *http.Request, result *Result, status int) {
+	var (
+		body        []byte
+		err         error
+		contentType string
+	)
+
+	switch negotiate(r.Header.Get("Accept"), []string{MediaTypeJSON, MediaTypeProblem, MediaTypeText}) {
+	case MediaTypeProblem:
+		contentType = MediaTypeProblem
+		body, err = json.Marshal(NewProblem(result, status))
+	case MediaTypeText:
+		contentType = MediaTypeText + "; charset=utf-8"
+
+		var b strings.Builder
+
+		for _, e := range result.Errors {
+			b.WriteString(e.Message)
+			b.WriteByte('\n')
+		}
+
+		body = []byte(b.String())
+	default:
+		contentType = MediaTypeJSON + "; charset=utf-8"
+		body, err = json.Marshal(result)
+	}
+
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// negotiate returns the media type in offers that matches the Accept header best.
+// The quality of an offer is the one of the most specific media range that matches it, and ties are broken by order of offers.
+// It returns the first offer when the header is empty or nothing matches.
+func negotiate(accept string, offers []string) string {
+	if strings.TrimSpace(accept) == "" {
+		return offers[0]
+	}
+
+	best, bestQ := offers[0], 0.0
+
+	for _, offer := range offers {
+		q, specificity := 0.0, -1
+
+		for _, part := range strings.Split(accept, ",") {
+			mediaRange, params, _ := strings.Cut(part, ";")
+			s := mediaRangeSpecificity(strings.ToLower(strings.TrimSpace(mediaRange)), offer)
+
+			if s <= specificity {
+				continue
+			}
+
+			q, specificity = 1.0, s
+
+			for _, param := range strings.Split(params, ";") {
+				if v, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+					if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+						q = parsed
+					}
+				}
+			}
+		}
+
+		if q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+
+	return best
+}
+
+// mediaRangeSpecificity returns 2 when the media range is the media type, 1 for `type/*`, 0 for `*/*` and -1 when it doesn't match.
+func mediaRangeSpecificity(mediaRange, mediaType string) int {
+	switch {
+	case mediaRange == mediaType:
+		return 2
+	case mediaRange == "*/*":
+		return 0
+	case strings.HasSuffix(mediaRange, "/*") && strings.HasPrefix(mediaType, mediaRange[:len(mediaRange)-1]):
+		return 1
+	}
+
+	return -1
+}
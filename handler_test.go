@@ -0,0 +1,204 @@
+package formspec
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// -----------------------------------------------------------------------------
+// Test formspec.Handler
+// -----------------------------------------------------------------------------
+
+func newHandlerTestServer() http.Handler {
+	s := New()
+	s.Rule("name", RuleRequired()).Filter(FilterTrim())
+	s.ContextRule("email", testEmailNotTaken).AllowBlank()
+
+	return Handler(s, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello " + ResultFrom(r.Context()).Value("name")))
+	}))
+}
+
+func TestHandler(t *testing.T) {
+	examples := []struct {
+		query          string
+		accept         string
+		acceptLanguage string
+		status         int
+		contentType    string
+		body           string
+	}{
+		{"name=+toqoz+", "", "", 200, "text/plain; charset=utf-8", "hello toqoz"},
+		{"", "", "", 422, "application/json; charset=utf-8", `{"errors":[{"field":"name","message":"name is required.","code":"required"}]}`},
+		{"", "application/json", "ja", 422, "application/json; charset=utf-8", `{"errors":[{"field":"name","message":"nameを入力してください。","code":"required"}]}`},
+		{"", "application/problem+json", "", 422, "application/problem+json", `{"type":"about:blank","title":"Unprocessable Entity","status":422,"detail":"The request has invalid parameters.","invalid-params":[{"name":"name","reason":"name is required.","code":"required"}]}`},
+		{"", "text/html, text/*;q=0.5, application/json;q=0.1", "", 422, "text/plain; charset=utf-8", "name is required.\n"},
+		{"name=toqoz&email=down@example.com", "", "", 500, "application/json; charset=utf-8", `{"errors":[{"field":"email","message":"email can't be validated now. Please try again later.","code":"internal"}]}`},
+		{"name=toqoz&email=down@example.com", "application/problem+json", "", 500, "application/problem+json", `{"type":"about:blank","title":"Internal Server Error","status":500,"invalid-params":[{"name":"email","reason":"email can't be validated now. Please try again later.","code":"internal"}]}`},
+	}
+
+	h := newHandlerTestServer()
+
+	for _, example := range examples {
+		req := httptest.NewRequest("GET", "/?"+example.query, nil)
+		req.Header.Set("Accept", example.accept)
+		req.Header.Set("Accept-Language", example.acceptLanguage)
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		if w.Code != example.status {
+			t.Errorf("When `%s` is given, expected status %d, but got %d", example.query, example.status, w.Code)
+		}
+
+		if ct := w.Header().Get("Content-Type"); ct != example.contentType {
+			t.Errorf("When `%s` is given, expected Content-Type `%s`, but got `%s`", example.query, example.contentType, ct)
+		}
+
+		if body := strings.TrimSuffix(w.Body.String(), "\n"); body != strings.TrimSuffix(example.body, "\n") {
+			t.Errorf("When `%s` is given, expected body\n%s\nbut got\n%s", example.query, example.body, body)
+		}
+	}
+}
+
+func TestHandler_JSON(t *testing.T) {
+	examples := []struct {
+		body   string
+		status int
+		resp   string
+	}{
+		{`{"name": " toqoz "}`, 200, "hello toqoz"},
+		{`{"name": {"first": "toqoz"}}`, 422, `{"errors":[{"field":"name","message":"name must be a string, number or boolean.","code":"json_type","params":{"actual":"object","expected":"scalar"}}]}`},
+		{`{"name": `, 400, `{"errors":[{"field":"","message":"The request body is not valid JSON.","code":"bad_json"}]}`},
+		{`{"name": "` + strings.Repeat("a", 100) + `"}`, 413, `{"errors":[{"field":"","message":"The request body is too large. Max is 100 bytes.","code":"body_too_large","params":{"max":100}}]}`},
+	}
+
+	defer func(limit int64) { JSONBodyLimit = limit }(JSONBodyLimit)
+	JSONBodyLimit = 100
+
+	h := newHandlerTestServer()
+
+	for _, example := range examples {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(example.body))
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		if w.Code != example.status || strings.TrimSpace(w.Body.String()) != example.resp {
+			t.Errorf("When `%s` is given, expected (%d, %s), but got (%d, %s)", example.body, example.status, example.resp, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestHandler_JSONProblem(t *testing.T) {
+	defer func(limit int64) { JSONBodyLimit = limit }(JSONBodyLimit)
+	JSONBodyLimit = 10
+
+	examples := []struct {
+		body     string
+		status   int
+		resp     string
+		expected *Error
+	}{
+		{
+			`{"name": "toqoz"}`, 413,
+			`{"type":"about:blank","title":"Request Entity Too Large","status":413,"detail":"The request body is too large. Max is 10 bytes.","invalid-params":[],"code":"body_too_large","params":{"max":10}}`,
+			&Error{Message: "The request body is too large. Max is 10 bytes.", Code: "body_too_large", Params: map[string]interface{}{"max": float64(10)}},
+		},
+		{
+			`{"name"`, 400,
+			`{"type":"about:blank","title":"Bad Request","status":400,"detail":"The request body is not valid JSON.","invalid-params":[],"code":"bad_json"}`,
+			&Error{Message: "The request body is not valid JSON.", Code: "bad_json"},
+		},
+	}
+
+	for _, example := range examples {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(example.body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/problem+json")
+
+		w := httptest.NewRecorder()
+		newHandlerTestServer().ServeHTTP(w, req)
+
+		if w.Code != example.status || w.Header().Get("Content-Type") != "application/problem+json" || strings.TrimSpace(w.Body.String()) != example.resp {
+			t.Errorf("When `%s` is given, expected (%d, %s), but got (%d, %s)", example.body, example.status, example.resp, w.Code, w.Body.String())
+		}
+
+		// Clients take the error of the body out of the problem details.
+		r, err := ParseProblem(w.Body.Bytes())
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if r.Ok || len(r.Errors) != 1 || !reflect.DeepEqual(r.Errors[0], example.expected) {
+			t.Errorf("When `%s` is given, expected the failed result that has %+v, but got %+v", example.body, example.expected, r.Errors)
+		}
+	}
+}
+
+func TestHandler_JSONBody(t *testing.T) {
+	s := New()
+	s.Rule("name", RuleRequired())
+
+	h := Handler(s, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		w.Write(body)
+	}))
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"name": "toqoz"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	if w.Code != 200 || w.Body.String() != `{"name": "toqoz"}` {
+		t.Errorf("expected the body to be read by the next handler, but got (%d, %s)", w.Code, w.Body.String())
+	}
+}
+
+func TestResultFrom(t *testing.T) {
+	if r := ResultFrom(context.Background()); r != nil {
+		t.Errorf("expected nil, but got %v", r)
+	}
+
+	r := NewOkResult()
+
+	if got := ResultFrom(WithResult(context.Background(), r)); got != r {
+		t.Errorf("expected %v, but got %v", r, got)
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	offers := []string{MediaTypeJSON, MediaTypeProblem, MediaTypeText}
+
+	examples := []struct {
+		accept   string
+		expected string
+	}{
+		{"", MediaTypeJSON},
+		{"*/*", MediaTypeJSON},
+		{"image/png", MediaTypeJSON},
+		{"application/problem+json, application/json;q=0.9", MediaTypeProblem},
+		{"application/*;q=0.2, text/plain", MediaTypeText},
+		{"*/*;q=0.1, application/json;q=0", MediaTypeProblem},
+		{"Text/Plain", MediaTypeText},
+	}
+
+	for _, example := range examples {
+		if got := negotiate(example.accept, offers); got != example.expected {
+			t.Errorf("When `%s` is given, expected `%s`, but got `%s`", example.accept, example.expected, got)
+		}
+	}
+}
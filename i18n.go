@@ -0,0 +1,197 @@
+package formspec
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Catalog has message templates for error codes in locales.
+//
+// Templates are text/template. Their data has `Label` (the label of the field), `Refs` (labels of fields that the rule refers to)
+// and params of the error with capitalized keys. e.g. "{{.Label}} is too long. Max is {{.Max}} character."
+// The template for code "item" builds the label of an item of multiple values from `Label` and `Index`.
+type Catalog interface {
+	// Message returns the message template for the code in the locale.
+	Message(locale, code string) (string, bool)
+	// Locales returns locales that the catalog supports.
+	Locales() []string
+}
+
+// MapCatalog is a Catalog of map[locale]map[code]template.
+// If the locale like "ja-JP" is not found, its language "ja" is used.
+type MapCatalog map[string]map[string]string
+
+func (c MapCatalog) Message(locale, code string) (string, bool) {
+	for {
+		if m, ok := c[locale][code]; ok {
+			return m, true
+		}
+
+		i := strings.LastIndexAny(locale, "-_")
+
+		if i < 0 {
+			return "", false
+		}
+
+		locale = locale[:i]
+	}
+}
+
+func (c MapCatalog) Locales() []string {
+	var locales []string
+
+	for locale := range c {
+		locales = append(locales, locale)
+	}
+
+	sort.Strings(locales)
+	return locales
+}
+
+// DefaultCatalog is used by Formspec that doesn't have Catalog. It has English (en) and Japanese (ja).
+var DefaultCatalog = MapCatalog{
+	"en": {
+		"item":                "{{.Label}} item {{.Index}}",
+		"required":            "{{.Label}} is required.",
+		"max_len":             "{{.Label}} is too long. Max is {{.Max}} character.",
+		"min_len":             "{{.Label}} is too short. Min is {{.Min}} character.",
+		"format":              "{{.Label}} is invalid.",
+		"in":                  "{{.Label}} is not included in the list.",
+		"number":              "{{.Label}} must be number.",
+		"int":                 "{{.Label}} must be integer.",
+		"bool":                "{{.Label}} must be boolean.",
+		"time":                "{{.Label}} must be time formatted as {{.Layout}}.",
+		"float_less_than":     "{{.Label}} must be less than {{.Value}}",
+		"float_greater_than":  "{{.Label}} must be greater than {{.Value}}",
+		"int_less_than":       "{{.Label}} must be less than {{.Value}}",
+		"int_greater_than":    "{{.Label}} must be greater than {{.Value}}",
+		"min_items":           "{{.Label}} must have at least {{.Min}} items.",
+		"max_items":           "{{.Label}} must have at most {{.Max}} items.",
+		"unique_items":        "{{.Label}} must not have duplicate items. Item {{.Index}} is a duplicate.",
+		"file_max_size":       "{{.Label}} is too large. Max is {{.Max}} bytes.",
+		"file_mime_type":      "{{.Label}} has unsupported file type {{.Detected}}.",
+		"file_ext":            "{{.Label}} has unsupported file extension.",
+		"image":               "{{.Label}} must be PNG, JPEG or GIF image.",
+		"image_max_dimension": "{{.Label}} is too large. Max is {{.Width}}x{{.Height}} pixels.",
+		"image_min_dimension": "{{.Label}} is too small. Min is {{.Width}}x{{.Height}} pixels.",
+		"equal_to_field":      "{{.Label}} must be equal to {{.Refs}}.",
+		"not_equal_to_field":  "{{.Label}} must not be equal to {{.Refs}}.",
+		"less_than_field":     "{{.Label}} must be less than {{.Refs}}.",
+		"greater_than_field":  "{{.Label}} must be greater than {{.Refs}}.",
+		"before_field":        "{{.Label}} must be before {{.Refs}}.",
+		"after_field":         "{{.Label}} must be after {{.Refs}}.",
+		"at_least_one_of":     "{{.Label}} requires at least one of {{.Refs}}.",
+		"exactly_one_of":      "{{.Label}} requires exactly one of {{.Refs}}.",
+		"mutually_exclusive":  "{{.Label}} allows only one of {{.Refs}}.",
+		"internal":            "{{.Label}} can't be validated now. Please try again later.",
+		"unknown":             "{{.Label}} is not allowed.",
+		"json_type":           "{{.Label}} must be {{if eq .Expected \"array\"}}an array of strings, numbers or booleans{{else}}a string, number or boolean{{end}}.",
+	},
+	"ja": {
+		"item":                "{{.Label}}[{{.Index}}]",
+		"required":            "{{.Label}}を入力してください。",
+		"max_len":             "{{.Label}}は{{.Max}}文字以内で入力してください。",
+		"min_len":             "{{.Label}}は{{.Min}}文字以上で入力してください。",
+		"format":              "{{.Label}}の形式が正しくありません。",
+		"in":                  "{{.Label}}は一覧にない値です。",
+		"number":              "{{.Label}}は数値で入力してください。",
+		"int":                 "{{.Label}}は整数で入力してください。",
+		"bool":                "{{.Label}}は真偽値で入力してください。",
+		"time":                "{{.Label}}は{{.Layout}}の形式で入力してください。",
+		"float_less_than":     "{{.Label}}は{{.Value}}より小さい値にしてください。",
+		"float_greater_than":  "{{.Label}}は{{.Value}}より大きい値にしてください。",
+		"int_less_than":       "{{.Label}}は{{.Value}}より小さい値にしてください。",
+		"int_greater_than":    "{{.Label}}は{{.Value}}より大きい値にしてください。",
+		"min_items":           "{{.Label}}は{{.Min}}個以上選択してください。",
+		"max_items":           "{{.Label}}は{{.Max}}個以下で選択してください。",
+		"unique_items":        "{{.Label}}に重複した項目があります。",
+		"file_max_size":       "{{.Label}}のファイルサイズは{{.Max}}バイト以下にしてください。",
+		"file_mime_type":      "{{.Label}}は対応していない形式のファイルです。({{.Detected}})",
+		"file_ext":            "{{.Label}}は対応していない拡張子のファイルです。",
+		"image":               "{{.Label}}はPNG、JPEG、GIF形式の画像にしてください。",
+		"image_max_dimension": "{{.Label}}は{{.Width}}x{{.Height}}ピクセル以下の画像にしてください。",
+		"image_min_dimension": "{{.Label}}は{{.Width}}x{{.Height}}ピクセル以上の画像にしてください。",
+		"equal_to_field":      "{{.Label}}が{{.Refs}}と一致しません。",
+		"not_equal_to_field":  "{{.Label}}は{{.Refs}}と異なる値にしてください。",
+		"less_than_field":     "{{.Label}}は{{.Refs}}より小さい値にしてください。",
+		"greater_than_field":  "{{.Label}}は{{.Refs}}より大きい値にしてください。",
+		"before_field":        "{{.Label}}は{{.Refs}}より前の日時にしてください。",
+		"after_field":         "{{.Label}}は{{.Refs}}より後の日時にしてください。",
+		"at_least_one_of":     "{{.Refs}}のいずれかを入力してください。",
+		"exactly_one_of":      "{{.Refs}}のいずれか1つだけを入力してください。",
+		"mutually_exclusive":  "{{.Refs}}は1つだけ入力してください。",
+		"internal":            "{{.Label}}を確認できませんでした。時間をおいて再度お試しください。",
+		"unknown":             "{{.Label}}は送信できない項目です。",
+		"json_type":           "{{.Label}}は{{if eq .Expected \"array\"}}文字列、数値、真偽値の配列{{else}}文字列、数値、真偽値のいずれか{{end}}にしてください。",
+	},
+}
+
+// ValidateLocale validates the form, and builds error messages in the locale by Formspec.Catalog (or DefaultCatalog).
+// Messages of codes that are not in the catalog, and messages overridden by Rule.FullMessage/Rule.Message are not localized.
+func (f *Formspec) ValidateLocale(form Form, locale string) *Result {
+	return f.validateAll(form, f.messenger(locale))
+}
+
+// ValidateRequest validates the request with the locale that matches its Accept-Language header. See ValidateLocale.
+func (f *Formspec) ValidateRequest(r *http.Request) *Result {
+	return f.ValidateLocale(r, MatchLocale(r.Header.Get("Accept-Language"), f.catalog().Locales()))
+}
+
+func (f *Formspec) catalog() Catalog {
+	if f.Catalog != nil {
+		return f.Catalog
+	}
+
+	return DefaultCatalog
+}
+
+// MatchLocale returns the locale in locales that matches the Accept-Language header best.
+// It returns "" when nothing matches.
+func MatchLocale(acceptLanguage string, locales []string) string {
+	type candidate struct {
+		tag string
+		q   float64
+	}
+
+	var candidates []candidate
+
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+
+		if tag != "" && q > 0 {
+			candidates = append(candidates, candidate{tag: tag, q: q})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+
+	for _, c := range candidates {
+		// "ja-JP" matches "ja-JP", and then "ja".
+		for tag := c.tag; tag != ""; {
+			for _, locale := range locales {
+				if strings.EqualFold(locale, tag) {
+					return locale
+				}
+			}
+
+			i := strings.LastIndexAny(tag, "-_")
+
+			if i < 0 {
+				break
+			}
+
+			tag = tag[:i]
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,110 @@
+package formspec
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestValidateLocale(t *testing.T) {
+	s := New()
+	s.Label("name", "名前").Label("password", "パスワード").Label("password_confirmation", "パスワード(確認)")
+	s.Rule("name", RuleRequired())
+	s.Rule("nick", RuleMaxLen(3))
+	s.Rule("password_confirmation", RuleEqualToField("password"))
+	s.MultiRule("ids", RuleEach(RuleInt()))
+	s.Rule("age", RuleInt()).Message("は数字で!")
+
+	f := Values(url.Values{"nick": {"toqoz"}, "password": {"a"}, "password_confirmation": {"b"}, "ids": {"1", "x"}, "age": {"x"}})
+
+	expected := []string{
+		"名前を入力してください。",
+		"nickは3文字以内で入力してください。",
+		"パスワード(確認)がパスワードと一致しません。",
+		"ids[1]は整数で入力してください。",
+		"age は数字で!",
+	}
+
+	r := s.ValidateLocale(f, "ja-JP")
+
+	if len(r.Errors) != len(expected) {
+		t.Fatalf("expected %d errors, but got %v", len(expected), r.Errors)
+	}
+
+	for i, e := range expected {
+		if r.Errors[i].Message != e {
+			t.Errorf("expected error `%s`, but got `%s`", e, r.Errors[i].Message)
+		}
+	}
+
+	// Validate is not affected.
+	if r := s.Validate(f); r.Errors[0].Message != "名前 is required." {
+		t.Errorf("expected error `名前 is required.`, but got `%s`", r.Errors[0].Message)
+	}
+
+	// English messages are same as default messages.
+	en := s.ValidateLocale(f, "en")
+	def := s.Validate(f)
+
+	for i := range def.Errors {
+		if en.Errors[i].Message != def.Errors[i].Message {
+			t.Errorf("expected error `%s`, but got `%s`", def.Errors[i].Message, en.Errors[i].Message)
+		}
+	}
+}
+
+func TestValidateLocale_Catalog(t *testing.T) {
+	s := New()
+	s.Catalog = MapCatalog{"fr": {"required": "{{.Label}} est obligatoire."}}
+	s.Rule("nom", RuleRequired())
+	s.Rule("age", RuleInt())
+
+	r := s.ValidateLocale(Values(url.Values{"age": {"x"}}), "fr-CA")
+
+	if r.Errors[0].Message != "nom est obligatoire." {
+		t.Errorf("expected error `nom est obligatoire.`, but got `%s`", r.Errors[0].Message)
+	}
+
+	// The code not in catalog falls back to default message.
+	if r.Errors[1].Message != "age must be integer." {
+		t.Errorf("expected error `age must be integer.`, but got `%s`", r.Errors[1].Message)
+	}
+}
+
+func TestValidateRequest(t *testing.T) {
+	s := New()
+	s.Rule("name", RuleRequired())
+
+	req, _ := http.NewRequest("POST", "/", strings.NewReader(""))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept-Language", "fr;q=0.9, ja;q=0.8, en;q=0.5")
+
+	if r := s.ValidateRequest(req); r.Errors[0].Message != "nameを入力してください。" {
+		t.Errorf("expected Japanese message, but got `%s`", r.Errors[0].Message)
+	}
+}
+
+func TestMatchLocale(t *testing.T) {
+	locales := []string{"en", "ja"}
+
+	examples := []struct {
+		header   string
+		expected string
+	}{
+		{"ja", "ja"},
+		{"ja-JP,en;q=0.5", "ja"},
+		{"en-US,ja;q=0.9", "en"},
+		{"fr, ja;q=0.1", "ja"},
+		{"en;q=0.1, JA;q=0.5", "ja"},
+		{"ja;q=0, en;q=0.1", "en"},
+		{"fr", ""},
+		{"", ""},
+	}
+
+	for _, example := range examples {
+		if locale := MatchLocale(example.header, locales); locale != example.expected {
+			t.Errorf("Test MatchLocale: When `%s` is given, expected `%s`, but got `%s`", example.header, example.expected, locale)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package formspec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+// Messages used when the type of the JSON value doesn't match the rule. See JSONForm.
+var (
+	RuleMessageJSONTypeScalar = "must be a string, number or boolean."
+	RuleMessageJSONTypeArray  = "must be an array of strings, numbers or booleans."
+)
+
+// ErrBodyTooLarge is returned by ReadJSONForm when the body is larger than the limit.
+var ErrBodyTooLarge = errors.New("formspec: request body is too large")
+
+// JSONForm is a Form that has values of a JSON document. It satisfies MultiForm and KeyedForm.
+//
+// Values are addressed with paths like `user.address.zip` and `items[2].sku`.
+// Strings are used as they are, numbers are used as they are written in the document (e.g. "1.50", "1e3"),
+// booleans are "true" or "false" and null is "".
+// Arrays of them are values of MultiForm. e.g. FormValues("tags") returns all items of `"tags": ["a", "b"]`,
+// and FormValue("tags[1]") returns "b".
+//
+// When a rule reads an object or an array of objects, or a single-value rule reads an array,
+// the rule fails with the error that has code "json_type" instead of being called.
+// Its params are "expected" ("scalar" or "array") and "actual" (See JSONForm.Kind). It is reported once per field.
+type JSONForm struct {
+	values map[string][]string
+	kinds  map[string]string
+	// Arrays that have only strings, numbers, booleans and nulls.
+	scalarArrays map[string]bool
+	keys         []string
+	// The JSON document. See JSONForm.Bytes.
+	data []byte
+}
+
+// NewJSONForm parses the JSON document. The top level value must be an object.
+func NewJSONForm(data []byte) (*JSONForm, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+
+	var v interface{}
+
+	if err := d.Decode(&v); err != nil {
+		return nil, fmt.Errorf("formspec: bad JSON: %v", err)
+	}
+
+	if _, err := d.Token(); err != io.EOF {
+		return nil, fmt.Errorf("formspec: bad JSON: unexpected data after the top level value")
+	}
+
+	if _, ok := v.(map[string]interface{}); !ok {
+		return nil, fmt.Errorf("formspec: bad JSON: top level value must be an object")
+	}
+
+	f := &JSONForm{values: map[string][]string{}, kinds: map[string]string{}, scalarArrays: map[string]bool{}, data: data}
+	f.add("", v, false)
+	sort.Strings(f.keys)
+	return f, nil
+}
+
+// Bytes returns the JSON document that the form is parsed from. e.g. the body read by ReadJSONForm
+func (f *JSONForm) Bytes() []byte {
+	return f.data
+}
+
+// ReadJSONForm reads the JSON body of the request that is at most limit bytes. See NewJSONForm.
+// It returns ErrBodyTooLarge when the body is larger than limit.
+func ReadJSONForm(r *http.Request, limit int64) (*JSONForm, error) {
+	data, err := io.ReadAll(io.LimitReader(r.Body, limit+1))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(data)) > limit {
+		return nil, ErrBodyTooLarge
+	}
+
+	return NewJSONForm(data)
+}
+
+// add adds the value at the path. item is true for items of arrays, which are listed in keys by the path of the array.
+func (f *JSONForm) add(path string, v interface{}, item bool) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		f.kinds[path] = "object"
+
+		for k, child := range v {
+			if path == "" {
+				f.add(k, child, false)
+			} else {
+				f.add(path+"."+k, child, false)
+			}
+		}
+	case []interface{}:
+		f.kinds[path] = "array"
+		f.values[path] = []string{}
+		f.scalarArrays[path] = true
+
+		if !item {
+			f.keys = append(f.keys, path)
+		}
+
+		for i, child := range v {
+			f.add(path+"["+strconv.Itoa(i)+"]", child, true)
+
+			if s, ok := jsonScalar(child); ok {
+				f.values[path] = append(f.values[path], s)
+			} else {
+				f.scalarArrays[path] = false
+			}
+		}
+	default:
+		s, _ := jsonScalar(v)
+		f.kinds[path] = jsonKind(v)
+		f.values[path] = []string{s}
+
+		if !item {
+			f.keys = append(f.keys, path)
+		}
+	}
+}
+
+func jsonScalar(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	case nil:
+		return "", true
+	}
+
+	return "", false
+}
+
+func jsonKind(v interface{}) string {
+	switch v.(type) {
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+
+	return "null"
+}
+
+func (f *JSONForm) FormValue(path string) string {
+	if values := f.values[path]; len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
+
+func (f *JSONForm) FormValues(path string) []string {
+	return f.values[path]
+}
+
+// FormKeys returns paths of values in sorted order. Arrays are listed by their paths, not by paths of their items.
+func (f *JSONForm) FormKeys() []string {
+	return f.keys
+}
+
+// Kind returns the JSON type of the value at the path. It is "object", "array", "string", "number", "boolean", "null",
+// or "" when there is no value.
+func (f *JSONForm) Kind(path string) string {
+	return f.kinds[path]
+}
+
+// typeError returns the error when the value at the path can't be read by the rule. multi is true for multi-value rules.
+func (f *JSONForm) typeError(path string, multi bool) *RuleError {
+	kind := f.kinds[path]
+	params := map[string]interface{}{"expected": "scalar", "actual": kind}
+
+	switch {
+	case multi && (kind == "object" || kind == "array" && !f.scalarArrays[path]):
+		params["expected"] = "array"
+		return ruleErrorf("json_type", params, RuleMessageJSONTypeArray)
+	case !multi && (kind == "object" || kind == "array"):
+		return ruleErrorf("json_type", params, RuleMessageJSONTypeScalar)
+	}
+
+	return nil
+}
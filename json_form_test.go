@@ -0,0 +1,148 @@
+package formspec
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// -----------------------------------------------------------------------------
+// Test formspec.JSONForm
+// -----------------------------------------------------------------------------
+
+const jsonFormTestBody = `{
+  "user": {"name": "toqoz", "age": 20, "admin": false, "note": null, "address": {"zip": "1000001"}},
+  "price": 1.50,
+  "big": 12345678901234567890,
+  "tags": ["a", 1, true],
+  "items": [{"sku": "A-1", "qty": 2}, {"sku": "B-2"}],
+  "matrix": [[1, 2]]
+}`
+
+func TestJSONForm(t *testing.T) {
+	f, err := NewJSONForm([]byte(jsonFormTestBody))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	examples := []struct {
+		path     string
+		expected string
+	}{
+		{"user.name", "toqoz"},
+		{"user.age", "20"},
+		{"user.admin", "false"},
+		{"user.note", ""},
+		{"user.address.zip", "1000001"},
+		{"user.address", ""},
+		{"price", "1.50"},
+		{"big", "12345678901234567890"},
+		{"tags", "a"},
+		{"tags[1]", "1"},
+		{"items[1].sku", "B-2"},
+		{"items[0].qty", "2"},
+		{"matrix[0][1]", "2"},
+		{"unknown", ""},
+	}
+
+	for _, example := range examples {
+		if got := f.FormValue(example.path); got != example.expected {
+			t.Errorf("When `%s` is given, expected `%s`, but got `%s`", example.path, example.expected, got)
+		}
+	}
+
+	if values := f.FormValues("tags"); !reflect.DeepEqual(values, []string{"a", "1", "true"}) {
+		t.Errorf("expected tags [a 1 true], but got %v", values)
+	}
+
+	expectedKeys := []string{"big", "items", "items[0].qty", "items[0].sku", "items[1].sku", "matrix", "price", "tags",
+		"user.address.zip", "user.admin", "user.age", "user.name", "user.note"}
+
+	if keys := f.FormKeys(); !reflect.DeepEqual(keys, expectedKeys) {
+		t.Errorf("expected keys %v, but got %v", expectedKeys, keys)
+	}
+
+	if kind := f.Kind("user"); kind != "object" {
+		t.Errorf("expected kind `object`, but got `%s`", kind)
+	}
+}
+
+func TestJSONForm_Validate(t *testing.T) {
+	s := New()
+	s.Rule("user.name", RuleRequired())
+	s.Rule("user.name", RuleMaxLen(3))
+	s.Rule("user.address", RuleRequired())
+	s.Rule("user.age", RuleIntGreaterThan(20))
+	s.Rule("items[1].qty", RuleRequired())
+	s.MultiRule("tags", RuleMaxItems(2))
+	s.MultiRule("items", RuleMaxItems(5))
+	s.Rule("price", RuleNumber())
+
+	f, err := NewJSONForm([]byte(jsonFormTestBody))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := s.Validate(f)
+
+	expected := []string{
+		"user.name is too long. Max is 3 character.",
+		"user.address must be a string, number or boolean.",
+		"user.age must be greater than 20",
+		"items[1].qty is required.",
+		"tags must have at most 2 items.",
+		"items must be an array of strings, numbers or booleans.",
+	}
+
+	if len(r.Errors) != len(expected) {
+		t.Fatalf("expected %d errors, but got %v", len(expected), r.Errors)
+	}
+
+	for i, e := range expected {
+		if r.Errors[i].Message != e {
+			t.Errorf("expected error `%s`, but got `%s`", e, r.Errors[i].Message)
+		}
+	}
+
+	// Type errors are reported once per field.
+	s.Rule("user", RuleRequired())
+	s.Rule("user", RuleMaxLen(3))
+
+	if r := s.ValidateLocale(f, "ja"); r.Errors[len(r.Errors)-1].Message != "userは文字列、数値、真偽値のいずれかにしてください。" || len(r.Errors) != len(expected)+1 {
+		t.Errorf("expected a type error of `user`, but got %v", r.Errors)
+	}
+}
+
+func TestReadJSONForm(t *testing.T) {
+	examples := []struct {
+		body     string
+		limit    int64
+		expected string
+	}{
+		{`{"name": "toqoz"}`, 17, ""},
+		{`{"name": "toqoz"}`, 16, "too large"},
+		{`{"name": `, 100, "bad JSON"},
+		{`["toqoz"]`, 100, "must be an object"},
+		{`{"name": "toqoz"} {}`, 100, "unexpected data"},
+	}
+
+	for _, example := range examples {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(example.body))
+		f, err := ReadJSONForm(req, example.limit)
+
+		if example.expected == "" {
+			if err != nil || f.FormValue("name") != "toqoz" {
+				t.Errorf("When `%s` is given, expected name `toqoz`, but got (%v, %v)", example.body, f, err)
+			}
+
+			continue
+		}
+
+		if err == nil || !strings.Contains(err.Error(), example.expected) {
+			t.Errorf("When `%s` is given, expected error `%s`, but got %v", example.body, example.expected, err)
+		}
+	}
+}
@@ -0,0 +1,489 @@
+package formspec
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// LoadError is returned when a spec file can't be loaded.
+type LoadError struct {
+	File string // empty when the spec is not loaded from a file
+	Line int    // 1-based line of the problem. 0 means unknown.
+	Msg  string
+}
+
+func (e *LoadError) Error() string {
+	where := e.File
+
+	if e.Line > 0 {
+		if where != "" {
+			where += ":"
+		}
+
+		where += fmt.Sprintf("%d", e.Line)
+	}
+
+	if where == "" {
+		return "formspec: " + e.Msg
+	}
+
+	return fmt.Sprintf("formspec: %s: %s", where, e.Msg)
+}
+
+// LoadFile loads *Formspec from JSON (.json) or YAML (.yaml, .yml) spec file.
+// Rules and filters are looked up in DefaultRegistry.
+//
+//	fields:
+//	  - field: name
+//	    rules: [required, "maxlen:20"]
+//	    filters: [trim, "truncate:20"]
+//	  - field: age
+//	    allow_blank: true
+//	    rules:
+//	      - rule: intgt
+//	        params: [0]
+//	        message: must be positive.
+//
+// The spec has `fields`, and options `bail`, `fail_fast`, `strict`, `partial` and `allow` (See Formspec.Bail, Formspec.FailFast, Formspec.Strict, Formspec.Partial and Formspec.Allow).
+// Each field has `field`, `rules`, `filters`, `bail`, `allow_blank`, `on`, `label`, `template`, `message` and `full_message`.
+// Each rule is a rule string (See Registry.Parse) or a mapping that has `rule`, `params`, `allow_blank`, `on`, `label`, `template`, `message` and `full_message`.
+// `on` is a scenario or a list of scenarios (See Rule.On), and `template` is a message template (See Rule.Template).
+// Options of the field are applied to its rules unless the rule overrides them.
+func LoadFile(filename string) (*Formspec, error) {
+	data, err := os.ReadFile(filename)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var f *Formspec
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		f, err = ParseJSON(data)
+	case ".yaml", ".yml":
+		f, err = ParseYAML(data)
+	default:
+		return nil, &LoadError{File: filename, Msg: "unknown spec file extension"}
+	}
+
+	if lerr, ok := err.(*LoadError); ok {
+		lerr.File = filename
+	}
+
+	return f, err
+}
+
+// ParseJSON parses JSON spec. See LoadFile for the format.
+func ParseJSON(data []byte) (*Formspec, error) {
+	n, err := parseJSONNode(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return buildSpec(n)
+}
+
+// ParseYAML parses YAML spec. See LoadFile for the format.
+// Only the subset of YAML is supported: block mappings and sequences, flow sequences, plain and quoted scalars, and comments.
+func ParseYAML(data []byte) (*Formspec, error) {
+	n, err := parseYAMLNode(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return buildSpec(n)
+}
+
+// ----------------------------------------------------------------------------
+// Spec nodes
+// ----------------------------------------------------------------------------
+
+type nodeKind int
+
+const (
+	scalarNode nodeKind = iota
+	seqNode
+	mapNode
+	nullNode
+)
+
+func (k nodeKind) String() string {
+	switch k {
+	case scalarNode:
+		return "scalar"
+	case seqNode:
+		return "sequence"
+	case mapNode:
+		return "mapping"
+	}
+
+	return "null"
+}
+
+// specNode is a value in spec files that knows the line where it is written.
+type specNode struct {
+	kind  nodeKind
+	line  int
+	value string      // for scalarNode
+	items []*specNode // for seqNode
+	keys  []string    // for mapNode
+	vals  []*specNode // for mapNode
+}
+
+func (n *specNode) errorf(format string, a ...interface{}) error {
+	return &LoadError{Line: n.line, Msg: fmt.Sprintf(format, a...)}
+}
+
+func (n *specNode) expect(kind nodeKind) error {
+	if n.kind != kind {
+		return n.errorf("expected %s, but got %s", kind, n.kind)
+	}
+
+	return nil
+}
+
+func (n *specNode) bool() (bool, error) {
+	if n.kind == scalarNode {
+		switch n.value {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+	}
+
+	return false, n.errorf("expected true or false")
+}
+
+// scalars returns values of the scalar or the sequence of scalars.
+func (n *specNode) scalars() ([]string, error) {
+	if n.kind == scalarNode {
+		return []string{n.value}, nil
+	}
+
+	if err := n.expect(seqNode); err != nil {
+		return nil, err
+	}
+
+	values := make([]string, len(n.items))
+
+	for i, item := range n.items {
+		if err := item.expect(scalarNode); err != nil {
+			return nil, err
+		}
+
+		values[i] = item.value
+	}
+
+	return values, nil
+}
+
+// ----------------------------------------------------------------------------
+// Build *Formspec from nodes
+// ----------------------------------------------------------------------------
+
+// ruleOptions are options written in fields and rules of spec files.
+type ruleOptions struct {
+	allowBlank  bool
+	scenarios   []string
+	label       string
+	template    *template.Template
+	message     string
+	fullMessage string
+}
+
+func (o *ruleOptions) set(key string, n *specNode) (bool, error) {
+	var err error
+
+	switch key {
+	case "allow_blank":
+		o.allowBlank, err = n.bool()
+	case "on":
+		o.scenarios, err = n.scalars()
+	case "label":
+		err = n.expect(scalarNode)
+		o.label = n.value
+	case "template":
+		if err = n.expect(scalarNode); err == nil {
+			if o.template, err = parseTemplate(n.value); err != nil {
+				err = n.errorf("bad template: %v", err)
+			}
+		}
+	case "message":
+		err = n.expect(scalarNode)
+		o.message = n.value
+	case "full_message":
+		err = n.expect(scalarNode)
+		o.fullMessage = n.value
+	default:
+		return false, nil
+	}
+
+	return true, err
+}
+
+func (o ruleOptions) apply(rule *Rule) {
+	rule.allowBlank = o.allowBlank
+	rule.scenarios = o.scenarios
+	rule.label = o.label
+	rule.template = o.template
+	rule.message = o.message
+	rule.fullMessage = o.fullMessage
+}
+
+func buildSpec(root *specNode) (*Formspec, error) {
+	if err := root.expect(mapNode); err != nil {
+		return nil, err
+	}
+
+	f := New()
+
+	for i, key := range root.keys {
+		v := root.vals[i]
+
+		switch key {
+		case "bail":
+			b, err := v.bool()
+
+			if err != nil {
+				return nil, err
+			}
+
+			f.Bail = b
+		case "fail_fast":
+			b, err := v.bool()
+
+			if err != nil {
+				return nil, err
+			}
+
+			f.FailFast = b
+		case "strict":
+			b, err := v.bool()
+
+			if err != nil {
+				return nil, err
+			}
+
+			f.Strict = b
+		case "partial":
+			b, err := v.bool()
+
+			if err != nil {
+				return nil, err
+			}
+
+			f.Partial = b
+		case "allow":
+			fields, err := v.scalars()
+
+			if err != nil {
+				return nil, err
+			}
+
+			f.Allow(fields...)
+		case "fields":
+			if err := v.expect(seqNode); err != nil {
+				return nil, err
+			}
+
+			for _, field := range v.items {
+				if err := f.addFieldNode(field); err != nil {
+					return nil, err
+				}
+			}
+		default:
+			return nil, v.errorf("unknown key %q", key)
+		}
+	}
+
+	return f, nil
+}
+
+func (f *Formspec) addFieldNode(n *specNode) error {
+	if err := n.expect(mapNode); err != nil {
+		return err
+	}
+
+	var (
+		field   string
+		rules   *specNode
+		filters []DescribedFilter
+		bail    bool
+		opts    ruleOptions
+	)
+
+	for i, key := range n.keys {
+		v := n.vals[i]
+
+		if ok, err := opts.set(key, v); ok {
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		switch key {
+		case "field":
+			if err := v.expect(scalarNode); err != nil {
+				return err
+			}
+
+			field = v.value
+		case "bail":
+			b, err := v.bool()
+
+			if err != nil {
+				return err
+			}
+
+			bail = b
+		case "rules":
+			if err := v.expect(seqNode); err != nil {
+				return err
+			}
+
+			rules = v
+		case "filters":
+			names, err := v.scalars()
+
+			if err != nil {
+				return err
+			}
+
+			for _, name := range names {
+				filterFuncs, err := DefaultRegistry.ParseFilters(name)
+
+				if perr, ok := err.(*ParseError); ok {
+					return v.errorf("%s at column %d in %q", perr.Msg, perr.Column, perr.Input)
+				}
+
+				filters = append(filters, filterFuncs...)
+			}
+		default:
+			return v.errorf("unknown key %q", key)
+		}
+	}
+
+	if field == "" {
+		return n.errorf("field is required")
+	}
+
+	if bail {
+		f.BailField(field)
+	}
+
+	if rules == nil {
+		return nil
+	}
+
+	for _, rn := range rules.items {
+		added, err := f.addRuleNode(field, rn, opts)
+
+		if err != nil {
+			return err
+		}
+
+		for _, rule := range added {
+			for _, filter := range filters {
+				rule.Filter(filter)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (f *Formspec) addRuleNode(field string, n *specNode, opts ruleOptions) ([]*Rule, error) {
+	if n.kind == scalarNode {
+		rules, err := f.RuleString(field, n.value)
+
+		if perr, ok := err.(*ParseError); ok {
+			return nil, n.errorf("%s at column %d in %q", perr.Msg, perr.Column, perr.Input)
+		}
+
+		for _, rule := range rules {
+			allowBlank := rule.allowBlank
+			opts.apply(rule)
+			rule.allowBlank = rule.allowBlank || allowBlank
+		}
+
+		return rules, err
+	}
+
+	if err := n.expect(mapNode); err != nil {
+		return nil, err
+	}
+
+	var (
+		name string
+		args []string
+	)
+
+	for i, key := range n.keys {
+		v := n.vals[i]
+
+		if ok, err := opts.set(key, v); ok {
+			if err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		switch key {
+		case "rule":
+			if err := v.expect(scalarNode); err != nil {
+				return nil, err
+			}
+
+			name = v.value
+		case "params":
+			var err error
+
+			if args, err = v.scalars(); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, v.errorf("unknown key %q", key)
+		}
+	}
+
+	if name == "" {
+		return nil, n.errorf("rule is required")
+	}
+
+	rule := &Rule{Field: field}
+
+	if b, ok := DefaultRegistry.multiRules[name]; ok {
+		multiRuleFunc, err := b(args)
+
+		if err != nil {
+			return nil, n.errorf("bad parameter for %s: %v", name, err)
+		}
+
+		rule.MultiRuleFunc, rule.meta = multiRuleFunc.Func, multiRuleFunc.Meta
+	} else if b, ok := DefaultRegistry.rules[name]; ok {
+		ruleFunc, err := b(args)
+
+		if err != nil {
+			return nil, n.errorf("bad parameter for %s: %v", name, err)
+		}
+
+		rule.RuleFunc, rule.meta = ruleFunc.Func, ruleFunc.Meta
+	} else {
+		return nil, n.errorf("unknown rule %q", name)
+	}
+
+	opts.apply(rule)
+	f.Rules = append(f.Rules, rule)
+
+	return []*Rule{rule}, nil
+}
@@ -0,0 +1,111 @@
+package formspec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// parseJSONNode parses JSON into specNode keeping line numbers.
+func parseJSONNode(data []byte) (*specNode, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	n, err := decodeJSONNode(dec, data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, &LoadError{Line: lineAt(data, dec.InputOffset()), Msg: "unexpected data after top-level value"}
+	}
+
+	return n, nil
+}
+
+func decodeJSONNode(dec *json.Decoder, data []byte) (*specNode, error) {
+	tok, err := dec.Token()
+
+	if err != nil {
+		return nil, jsonError(err, dec, data)
+	}
+
+	n := &specNode{line: lineAt(data, dec.InputOffset())}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '[':
+			n.kind = seqNode
+
+			for dec.More() {
+				item, err := decodeJSONNode(dec, data)
+
+				if err != nil {
+					return nil, err
+				}
+
+				n.items = append(n.items, item)
+			}
+		case '{':
+			n.kind = mapNode
+
+			for dec.More() {
+				key, err := dec.Token()
+
+				if err != nil {
+					return nil, jsonError(err, dec, data)
+				}
+
+				val, err := decodeJSONNode(dec, data)
+
+				if err != nil {
+					return nil, err
+				}
+
+				n.keys = append(n.keys, key.(string))
+				n.vals = append(n.vals, val)
+			}
+		}
+
+		// closing delimiter
+		if _, err := dec.Token(); err != nil {
+			return nil, jsonError(err, dec, data)
+		}
+	case string:
+		n.kind, n.value = scalarNode, t
+	case json.Number:
+		n.kind, n.value = scalarNode, t.String()
+	case bool:
+		n.kind, n.value = scalarNode, fmt.Sprint(t)
+	case nil:
+		n.kind = nullNode
+	}
+
+	return n, nil
+}
+
+func jsonError(err error, dec *json.Decoder, data []byte) error {
+	offset := dec.InputOffset()
+
+	if serr, ok := err.(*json.SyntaxError); ok {
+		offset = serr.Offset
+	}
+
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+
+	return &LoadError{Line: lineAt(data, offset), Msg: err.Error()}
+}
+
+// lineAt returns 1-based line number of the offset in data.
+func lineAt(data []byte, offset int64) int {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
@@ -0,0 +1,181 @@
+package formspec
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const loadTestJSON = `{
+  "fields": [
+    {"field": "name", "rules": ["required", "maxlen:5"], "filters": ["trim|lower"]},
+    {"field": "age", "allow_blank": true, "rules": [
+      "int",
+      {"rule": "intgt", "params": [0], "message": "must be positive."}
+    ]},
+    {"field": "nick", "rules": ["required"], "full_message": "Please enter your cool nick."},
+    {"field": "tags", "label": "Tags", "rules": ["maxitems:2|unique"]}
+  ]
+}`
+
+const loadTestYAML = `# signup form
+fields:
+  - field: name
+    rules: [required, "maxlen:5"]
+    filters: [trim]
+  - field: age
+    allow_blank: true
+    rules:
+      - int
+      - rule: intgt
+        params: [0]
+        message: must be positive. # comment
+  - field: nick
+    rules:
+    - required
+    full_message: 'Please enter your cool nick.'
+  - field: tags
+    label: Tags
+    rules:
+      - maxitems:2|unique
+`
+
+func testLoadedSpec(t *testing.T, s *Formspec) {
+	if r := s.Validate(Values(url.Values{"name": {" toqoz "}, "nick": {"toqoz"}})); !r.Ok {
+		t.Errorf("validation error is not expected, but got %v", r.Errors)
+	}
+
+	r := s.Validate(Values(url.Values{"name": {"   "}, "age": {"0"}, "tags": {"a", "a", "b"}}))
+
+	expected := []string{
+		"name is required.",
+		"age must be positive.",
+		"Please enter your cool nick.",
+		"Tags must have at most 2 items.",
+		"Tags must not have duplicate items. Item 1 is a duplicate.",
+	}
+
+	if len(r.Errors) != len(expected) {
+		t.Fatalf("expected %d errors, but got %v", len(expected), r.Errors)
+	}
+
+	for i, e := range expected {
+		if r.Errors[i].Message != e {
+			t.Errorf("expected error `%s`, but got `%s`", e, r.Errors[i].Message)
+		}
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	s, err := ParseJSON([]byte(loadTestJSON))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testLoadedSpec(t, s)
+}
+
+func TestParseYAML(t *testing.T) {
+	s, err := ParseYAML([]byte(loadTestYAML))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testLoadedSpec(t, s)
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+
+	for name, content := range map[string]string{"spec.json": loadTestJSON, "spec.yml": loadTestYAML} {
+		filename := filepath.Join(dir, name)
+		os.WriteFile(filename, []byte(content), 0644)
+
+		s, err := LoadFile(filename)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		testLoadedSpec(t, s)
+	}
+
+	filename := filepath.Join(dir, "bad.yaml")
+	os.WriteFile(filename, []byte("fields:\n  - field: name\n    rules: [unknown]\n"), 0644)
+
+	if _, err := LoadFile(filename); err == nil || err.Error() != `formspec: `+filename+`:3: unknown rule "unknown" at column 1 in "unknown"` {
+		t.Errorf("expected error with file and line, but got %v", err)
+	}
+}
+
+func TestParseSpec_Errors(t *testing.T) {
+	examples := []struct {
+		json     string
+		yaml     string
+		line     int
+		expected string
+	}{
+		{
+			"{\n\"fields\": [\n{\"field\": \"name\", \"rules\": [\"unknown\"]}\n]}",
+			"fields:\n  - field: name\n    rules: [unknown]",
+			3, "unknown rule",
+		},
+		{
+			"{\n\"fields\": [\n{\"field\": \"name\",\n\"rules\": [{\"rule\": \"format\", \"params\": [\"(\"]}]}\n]}",
+			"fields:\n  - field: name\n    rules:\n      - rule: format\n        params: ['(']",
+			4, "bad parameter for format",
+		},
+		{
+			"{\n\"fields\": [\n{\"field\": \"name\", \"filters\": [\"unknown\"]}\n]}",
+			"fields:\n  - field: name\n    filters: [unknown]",
+			3, "unknown filter",
+		},
+		{
+			"{\n\"fields\": [\n{\"field\": \"name\",\n\"allow_blank\": \"yes\"}\n]}",
+			"fields:\n  - field: name\n    rules: []\n    allow_blank: yes",
+			4, "expected true or false",
+		},
+		{
+			"{\n\"fields\": [\n{\"field\": \"name\",\n\"template\": \"{{.Label\"}\n]}",
+			"fields:\n  - field: name\n    rules: []\n    template: '{{.Label'",
+			4, "bad template",
+		},
+		{
+			"{\n\"fields\": [{\"rules\": []}\n]}",
+			"fields:\n  - rules: []",
+			2, "field is required",
+		},
+		{
+			"{\n\"fieldz\": []}",
+			"\nfieldz: []",
+			2, "unknown key",
+		},
+		{
+			"{\n\"fields\": [\n{\"field\": \"name\",,}\n]}",
+			"fields:\n  - field: name\n   rules: []",
+			3, "",
+		},
+	}
+
+	for _, example := range examples {
+		_, jerr := ParseJSON([]byte(example.json))
+		_, yerr := ParseYAML([]byte(example.yaml))
+
+		for _, err := range []error{jerr, yerr} {
+			lerr, ok := err.(*LoadError)
+
+			if !ok {
+				t.Errorf("expected *LoadError, but got %v", err)
+				continue
+			}
+
+			if lerr.Line != example.line || !strings.Contains(lerr.Msg, example.expected) {
+				t.Errorf("expected error `%s` at line %d, but got %v", example.expected, example.line, lerr)
+			}
+		}
+	}
+}
@@ -0,0 +1,327 @@
+package formspec
+
+import (
+	"strconv"
+	"strings"
+)
+
+// yamlLine is a non-blank line of YAML without comments.
+type yamlLine struct {
+	indent int
+	text   string
+	line   int
+}
+
+type yamlParser struct {
+	lines []*yamlLine
+	pos   int
+}
+
+// parseYAMLNode parses the subset of YAML into specNode keeping line numbers.
+func parseYAMLNode(data []byte) (*specNode, error) {
+	p := &yamlParser{}
+
+	for i, raw := range strings.Split(string(data), "\n") {
+		raw = strings.TrimRight(raw, " \r")
+		text := strings.TrimLeft(raw, " ")
+
+		if strings.HasPrefix(text, "\t") {
+			return nil, &LoadError{Line: i + 1, Msg: "tabs are not allowed for indentation"}
+		}
+
+		text = strings.TrimRight(stripYAMLComment(text), " ")
+
+		if text == "" || text == "---" {
+			continue
+		}
+
+		p.lines = append(p.lines, &yamlLine{indent: len(raw) - len(strings.TrimLeft(raw, " ")), text: text, line: i + 1})
+	}
+
+	if len(p.lines) == 0 {
+		return &specNode{kind: nullNode, line: 1}, nil
+	}
+
+	n, err := p.parseBlock(p.lines[0].indent)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos < len(p.lines) {
+		return nil, &LoadError{Line: p.lines[p.pos].line, Msg: "bad indentation"}
+	}
+
+	return n, nil
+}
+
+// stripYAMLComment removes `# comment` that is not in quotes.
+func stripYAMLComment(text string) string {
+	var quote byte
+
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else if c == '\\' && quote == '"' {
+				i++
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || text[i-1] == ' '):
+			return text[:i]
+		}
+	}
+
+	return text
+}
+
+func isYAMLSeqItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+func (p *yamlParser) parseBlock(indent int) (*specNode, error) {
+	if isYAMLSeqItem(p.lines[p.pos].text) {
+		return p.parseSeq(indent)
+	}
+
+	return p.parseMap(indent)
+}
+
+func (p *yamlParser) parseSeq(indent int) (*specNode, error) {
+	n := &specNode{kind: seqNode, line: p.lines[p.pos].line}
+
+	for p.pos < len(p.lines) {
+		l := p.lines[p.pos]
+
+		if l.indent < indent || (l.indent == indent && !isYAMLSeqItem(l.text)) {
+			break
+		}
+
+		if l.indent > indent || !isYAMLSeqItem(l.text) {
+			return nil, &LoadError{Line: l.line, Msg: "bad indentation"}
+		}
+
+		content := strings.TrimLeft(strings.TrimPrefix(l.text, "-"), " ")
+
+		if content == "" {
+			p.pos++
+
+			if p.pos >= len(p.lines) || p.lines[p.pos].indent <= indent {
+				n.items = append(n.items, &specNode{kind: nullNode, line: l.line})
+				continue
+			}
+
+			item, err := p.parseBlock(p.lines[p.pos].indent)
+
+			if err != nil {
+				return nil, err
+			}
+
+			n.items = append(n.items, item)
+			continue
+		}
+
+		if _, _, ok := splitYAMLKey(content); ok || isYAMLSeqItem(content) {
+			// `- key: value` starts a nested block at the column of the content.
+			l.indent += len(l.text) - len(content)
+			l.text = content
+
+			item, err := p.parseBlock(l.indent)
+
+			if err != nil {
+				return nil, err
+			}
+
+			n.items = append(n.items, item)
+			continue
+		}
+
+		item, err := parseYAMLScalar(content, l.line)
+
+		if err != nil {
+			return nil, err
+		}
+
+		n.items = append(n.items, item)
+		p.pos++
+	}
+
+	return n, nil
+}
+
+func (p *yamlParser) parseMap(indent int) (*specNode, error) {
+	n := &specNode{kind: mapNode, line: p.lines[p.pos].line}
+
+	for p.pos < len(p.lines) {
+		l := p.lines[p.pos]
+
+		if l.indent < indent || (l.indent == indent && isYAMLSeqItem(l.text)) {
+			break
+		}
+
+		if l.indent > indent {
+			return nil, &LoadError{Line: l.line, Msg: "bad indentation"}
+		}
+
+		key, rest, ok := splitYAMLKey(l.text)
+
+		if !ok {
+			return nil, &LoadError{Line: l.line, Msg: "expected `key: value`"}
+		}
+
+		p.pos++
+
+		var (
+			val *specNode
+			err error
+		)
+
+		switch {
+		case rest != "":
+			val, err = parseYAMLScalar(rest, l.line)
+		case p.pos < len(p.lines) && p.lines[p.pos].indent > indent:
+			val, err = p.parseBlock(p.lines[p.pos].indent)
+		case p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isYAMLSeqItem(p.lines[p.pos].text):
+			// Sequences can be at the same indentation as the key.
+			val, err = p.parseSeq(indent)
+		default:
+			val = &specNode{kind: nullNode, line: l.line}
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		n.keys = append(n.keys, key)
+		n.vals = append(n.vals, val)
+	}
+
+	return n, nil
+}
+
+// splitYAMLKey splits `key: value` into key and value.
+func splitYAMLKey(text string) (string, string, bool) {
+	var quote byte
+
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 {
+				quote = c
+			}
+		case c == '[' && i == 0:
+			return "", "", false
+		case c == ':' && (i+1 == len(text) || text[i+1] == ' '):
+			key, err := parseYAMLScalar(strings.TrimSpace(text[:i]), 0)
+
+			if err != nil || key.kind != scalarNode {
+				return "", "", false
+			}
+
+			return key.value, strings.TrimSpace(text[i+1:]), true
+		}
+	}
+
+	return "", "", false
+}
+
+func parseYAMLScalar(text string, line int) (*specNode, error) {
+	n := &specNode{kind: scalarNode, line: line}
+
+	switch {
+	case strings.HasPrefix(text, "["):
+		return parseYAMLFlowSeq(text, line)
+	case strings.HasPrefix(text, "{"), strings.HasPrefix(text, "&"), strings.HasPrefix(text, "*"),
+		strings.HasPrefix(text, "|"), strings.HasPrefix(text, ">"):
+		return nil, &LoadError{Line: line, Msg: "unsupported YAML syntax " + strconv.Quote(text[:1])}
+	case strings.HasPrefix(text, `"`):
+		v, err := strconv.Unquote(text)
+
+		if err != nil {
+			return nil, &LoadError{Line: line, Msg: "bad double-quoted string"}
+		}
+
+		n.value = v
+	case strings.HasPrefix(text, "'"):
+		if len(text) < 2 || !strings.HasSuffix(text, "'") {
+			return nil, &LoadError{Line: line, Msg: "bad single-quoted string"}
+		}
+
+		n.value = strings.Replace(text[1:len(text)-1], "''", "'", -1)
+	case text == "~" || text == "null":
+		n.kind = nullNode
+	default:
+		n.value = text
+	}
+
+	return n, nil
+}
+
+// parseYAMLFlowSeq parses flow sequence like `[a, "b", 'c']`. Nested collections are not supported.
+func parseYAMLFlowSeq(text string, line int) (*specNode, error) {
+	if !strings.HasSuffix(text, "]") {
+		return nil, &LoadError{Line: line, Msg: "unterminated flow sequence"}
+	}
+
+	n := &specNode{kind: seqNode, line: line}
+	inner := strings.TrimSpace(text[1 : len(text)-1])
+
+	if inner == "" {
+		return n, nil
+	}
+
+	var (
+		quote byte
+		start int
+	)
+
+	for i := 0; i <= len(inner); i++ {
+		if i < len(inner) {
+			c := inner[i]
+
+			if quote != 0 {
+				if c == quote {
+					quote = 0
+				} else if c == '\\' && quote == '"' {
+					i++
+				}
+
+				continue
+			}
+
+			if c == '"' || c == '\'' {
+				quote = c
+				continue
+			}
+
+			if c == '[' || c == '{' {
+				return nil, &LoadError{Line: line, Msg: "nested flow collections are not supported"}
+			}
+
+			if c != ',' {
+				continue
+			}
+		}
+
+		item, err := parseYAMLScalar(strings.TrimSpace(inner[start:i]), line)
+
+		if err != nil {
+			return nil, err
+		}
+
+		n.items = append(n.items, item)
+		start = i + 1
+	}
+
+	return n, nil
+}
@@ -0,0 +1,175 @@
+package formspec
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"text/template"
+	"unicode"
+)
+
+// Label sets the human label of the field for the rule. It is used prior to the label set by Formspec.Label.
+func (r *Rule) Label(label string) *Rule {
+	r.label = label
+	return r
+}
+
+// Template sets the template of the whole error message. It is used prior to Rule.fullMessage and Rule.message.
+// Its data has `Label`, `Message` (the message returned from the rule func), `Refs` (labels of fields that the rule refers to)
+// and params of the error with capitalized keys. It panics if the template can't be parsed.
+//
+//	s.Rule("user_name", formspec.RuleMaxLen(20)).Label("User name").Template("{{.Label}} must be at most {{.Max}} characters.")
+func (r *Rule) Template(text string) *Rule {
+	r.template = mustParseTemplate(text)
+	return r
+}
+
+// Template sets the template of error messages for the error code in the spec. See Rule.Template for its data.
+// It is used for errors that are not localized by ValidateLocale. It panics if the template can't be parsed.
+//
+//	s.Template("max_len", "{{.Label}} must be at most {{.Max}} characters.")
+func (f *Formspec) Template(code, text string) *Formspec {
+	if f.templates == nil {
+		f.templates = map[string]*template.Template{}
+	}
+
+	f.templates[code] = mustParseTemplate(text)
+	return f
+}
+
+// ----------------------------------------------------------------------------
+// messenger
+// ----------------------------------------------------------------------------
+
+// messenger builds error messages with labels of fields, templates and the catalog for the locale.
+type messenger struct {
+	labelOf   func(string) string
+	templates map[string]*template.Template
+	catalog   Catalog
+	locale    string
+}
+
+func (f *Formspec) messenger(locale string) *messenger {
+	return &messenger{labelOf: f.LabelOf, templates: f.templates, catalog: f.catalog(), locale: locale}
+}
+
+func (m *messenger) label(field string) string {
+	if m == nil || m.labelOf == nil {
+		return field
+	}
+
+	return m.labelOf(field)
+}
+
+// data returns data for message templates.
+func (m *messenger) data(err *RuleError, params map[string]interface{}, label, message string) map[string]interface{} {
+	data := map[string]interface{}{"Label": label, "Message": message, "Refs": ""}
+
+	if err != nil {
+		data["Refs"] = err.refLabels(m.label)
+	}
+
+	for k, v := range params {
+		data[capitalize(k)] = v
+	}
+
+	return data
+}
+
+// localize builds the message of the error from the template in the catalog for the locale, or the template of the spec.
+// Only *RuleError is localized, because messages of other errors may tell more than their codes.
+func (m *messenger) localize(err *RuleError, label, message string) (string, bool) {
+	if m == nil || err == nil {
+		return "", false
+	}
+
+	if err.item {
+		itemLabel, ok := m.execute("item", map[string]interface{}{"Label": label, "Index": err.Params["index"]})
+
+		if !ok {
+			itemLabel, _ = executeTemplate(mustParseTemplate(DefaultCatalog["en"]["item"]), map[string]interface{}{"Label": label, "Index": err.Params["index"]})
+		}
+
+		label = itemLabel
+	}
+
+	return m.execute(err.Code, m.data(err, err.Params, label, message))
+}
+
+// fieldError returns *Error of the field that is not returned from rules. e.g. "internal", "unknown"
+func (m *messenger) fieldError(field string, err *RuleError) *Error {
+	label := m.label(field)
+	e := &Error{Field: field, Message: fmt.Sprintf("%s %s", label, err.Message), Code: err.Code, Params: err.Params}
+
+	if s, ok := m.localize(err, label, err.Message); ok {
+		e.Message = s
+	}
+
+	return e
+}
+
+func (m *messenger) execute(code string, data map[string]interface{}) (string, bool) {
+	if m.locale != "" && m.catalog != nil {
+		if text, ok := m.catalog.Message(m.locale, code); ok {
+			if t, err := parseTemplate(text); err == nil {
+				if s, ok := executeTemplate(t, data); ok {
+					return s, true
+				}
+			}
+		}
+	}
+
+	if t, ok := m.templates[code]; ok {
+		return executeTemplate(t, data)
+	}
+
+	return "", false
+}
+
+func executeTemplate(t *template.Template, data map[string]interface{}) (string, bool) {
+	buf := &bytes.Buffer{}
+
+	if err := t.Execute(buf, data); err != nil {
+		return "", false
+	}
+
+	return buf.String(), true
+}
+
+var templates sync.Map // text -> *template.Template
+
+func parseTemplate(text string) (*template.Template, error) {
+	if t, ok := templates.Load(text); ok {
+		return t.(*template.Template), nil
+	}
+
+	t, err := template.New("").Option("missingkey=zero").Parse(text)
+
+	if err != nil {
+		return nil, err
+	}
+
+	templates.Store(text, t)
+	return t, nil
+}
+
+func mustParseTemplate(text string) *template.Template {
+	t, err := parseTemplate(text)
+
+	if err != nil {
+		panic(fmt.Sprintf("formspec: bad template %q: %v", text, err))
+	}
+
+	return t
+}
+
+// capitalize returns the key of params with the first letter in upper case. e.g. "max" -> "Max"
+func capitalize(key string) string {
+	if key == "" {
+		return key
+	}
+
+	r := []rune(key)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
@@ -0,0 +1,77 @@
+package formspec
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestTemplate(t *testing.T) {
+	s := New()
+	s.Label("user_name", "User name")
+	s.Template("max_len", "{{.Label}} must be at most {{.Max}} characters.")
+	s.Template("item", "{{.Label}} #{{.Index}}")
+	s.Rule("user_name", RuleMaxLen(3))
+	s.Rule("nick", RuleMaxLen(3)).Label("Nickname")
+	s.Rule("bio", RuleMaxLen(3)).Label("Bio").Template("{{.Label}}: {{.Message}} ({{.Max}})")
+	s.Rule("email", RuleRequired()).Label("Email")
+	s.Rule("age", RuleMaxLen(1)).Message("is too long.")
+	s.Rule("note", RuleMaxLen(1)).FullMessage("Note is too long.")
+	s.MultiRule("tags", RuleEach(RuleMaxLen(1)))
+	s.Rule("zip", Describe(func(v string, f Form) error {
+		return errors.New("is not a zip code.")
+	}, "zip", map[string]interface{}{"digits": 7})).Template("{{.Label}} must have {{.Digits}} digits.")
+
+	f := Values(url.Values{
+		"user_name": {"toqoz"},
+		"nick":      {"toqoz"},
+		"bio":       {"toqoz"},
+		"age":       {"20"},
+		"note":      {"toqoz"},
+		"tags":      {"a", "bc"},
+		"zip":       {"x"},
+	})
+
+	expected := []string{
+		"User name must be at most 3 characters.",
+		"Nickname must be at most 3 characters.",
+		"Bio: is too long. Max is 3 character. (3)",
+		"Email is required.",
+		"age is too long.",
+		"Note is too long.",
+		"tags #1 must be at most 1 characters.",
+		"zip must have 7 digits.",
+	}
+
+	r := s.Validate(f)
+
+	if len(r.Errors) != len(expected) {
+		t.Fatalf("expected %d errors, but got %v", len(expected), r.Errors)
+	}
+
+	for i, e := range expected {
+		if r.Errors[i].Message != e {
+			t.Errorf("expected error `%s`, but got `%s`", e, r.Errors[i].Message)
+		}
+	}
+
+	// Templates of the catalog are used prior to templates of the spec.
+	if r := s.ValidateLocale(f, "ja"); r.Errors[0].Message != "User nameは3文字以内で入力してください。" {
+		t.Errorf("expected localized error, but got `%s`", r.Errors[0].Message)
+	}
+
+	// Labels and templates are cloned.
+	if r := s.Clone().Validate(f); r.Errors[1].Message != expected[1] {
+		t.Errorf("expected error `%s`, but got `%s`", expected[1], r.Errors[1].Message)
+	}
+}
+
+func TestTemplate_Panic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for the bad template")
+		}
+	}()
+
+	New().Rule("name", RuleRequired()).Template("{{.Label")
+}
@@ -0,0 +1,129 @@
+package formspec
+
+import (
+	"fmt"
+	"mime/multipart"
+)
+
+// RuleMeta describes what a rule checks (or what a filter does) in machine-readable way. e.g. {Name: "max_len", Params: {"max": 20}}
+// It is used by introspection and exporters like Formspec.JSONSchema.
+type RuleMeta struct {
+	Name   string                 `json:"name"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+// DescribedRule is a RuleFunc that has RuleMeta. Describe and built-in rules return it.
+// Formspec.Rule adds it like RuleFunc, and the rule keeps the RuleMeta (See Rule.Meta).
+type DescribedRule struct {
+	Func RuleFunc
+	Meta *RuleMeta
+}
+
+// DescribedMultiRule is a MultiRuleFunc that has RuleMeta. See DescribedRule.
+type DescribedMultiRule struct {
+	Func MultiRuleFunc
+	Meta *RuleMeta
+}
+
+// DescribedFileRule is a FileRuleFunc that has RuleMeta. See DescribedRule.
+type DescribedFileRule struct {
+	Func FileRuleFunc
+	Meta *RuleMeta
+}
+
+// Describe attaches RuleMeta to the RuleFunc. All built-in rules are described by it.
+// You can describe your own rules, so that they appear in introspection and exporters.
+func Describe(ruleFunc RuleFunc, name string, params map[string]interface{}) DescribedRule {
+	return DescribedRule{Func: ruleFunc, Meta: &RuleMeta{Name: name, Params: params}}
+}
+
+// DescribeMulti attaches RuleMeta to the MultiRuleFunc. See Describe.
+func DescribeMulti(multiRuleFunc MultiRuleFunc, name string, params map[string]interface{}) DescribedMultiRule {
+	return DescribedMultiRule{Func: multiRuleFunc, Meta: &RuleMeta{Name: name, Params: params}}
+}
+
+// DescribeFile attaches RuleMeta to the FileRuleFunc. See Describe.
+func DescribeFile(fileRuleFunc FileRuleFunc, name string, params map[string]interface{}) DescribedFileRule {
+	return DescribedFileRule{Func: fileRuleFunc, Meta: &RuleMeta{Name: name, Params: params}}
+}
+
+// ruleFuncOf returns the RuleFunc and its RuleMeta of the value given to Formspec.Rule.
+// It panics if the value is neither RuleFunc (or a func of its signature) nor DescribedRule.
+func ruleFuncOf(v interface{}) (RuleFunc, *RuleMeta) {
+	switch v := v.(type) {
+	case DescribedRule:
+		return v.Func, v.Meta
+	case RuleFunc:
+		return v, nil
+	case func(string, Form) error:
+		return v, nil
+	}
+
+	panic(fmt.Sprintf("formspec: %T is not RuleFunc nor DescribedRule", v))
+}
+
+// multiRuleFuncOf returns the MultiRuleFunc and its RuleMeta of the value given to Formspec.MultiRule. See ruleFuncOf.
+func multiRuleFuncOf(v interface{}) (MultiRuleFunc, *RuleMeta) {
+	switch v := v.(type) {
+	case DescribedMultiRule:
+		return v.Func, v.Meta
+	case MultiRuleFunc:
+		return v, nil
+	case func([]string, Form) error:
+		return v, nil
+	}
+
+	panic(fmt.Sprintf("formspec: %T is not MultiRuleFunc nor DescribedMultiRule", v))
+}
+
+// fileRuleFuncOf returns the FileRuleFunc and its RuleMeta of the value given to Formspec.FileRule. See ruleFuncOf.
+func fileRuleFuncOf(v interface{}) (FileRuleFunc, *RuleMeta) {
+	switch v := v.(type) {
+	case DescribedFileRule:
+		return v.Func, v.Meta
+	case FileRuleFunc:
+		return v, nil
+	case func(*multipart.FileHeader, Form) error:
+		return v, nil
+	}
+
+	panic(fmt.Sprintf("formspec: %T is not FileRuleFunc nor DescribedFileRule", v))
+}
+
+// DescribedFilter is a FilterFunc that has RuleMeta. DescribeFilter and built-in filters return it.
+// Rule.Filter adds it like FilterFunc, and the rule keeps the RuleMeta (See Rule.FilterMetas).
+type DescribedFilter struct {
+	Func FilterFunc
+	Meta *RuleMeta
+}
+
+// DescribeFilter attaches RuleMeta to the FilterFunc. All built-in filters are described by it. See Describe.
+func DescribeFilter(filterFunc FilterFunc, name string, params map[string]interface{}) DescribedFilter {
+	return DescribedFilter{Func: filterFunc, Meta: &RuleMeta{Name: name, Params: params}}
+}
+
+// filterFuncOf returns the FilterFunc and its RuleMeta of the value given to Rule.Filter. See ruleFuncOf.
+func filterFuncOf(v interface{}) (FilterFunc, *RuleMeta) {
+	switch v := v.(type) {
+	case DescribedFilter:
+		return v.Func, v.Meta
+	case FilterFunc:
+		return v, nil
+	case func(string) string:
+		return v, nil
+	}
+
+	panic(fmt.Sprintf("formspec: %T is not FilterFunc nor DescribedFilter", v))
+}
+
+// Meta returns RuleMeta of the rule func. It returns nil when the rule func is not described.
+func (r *Rule) Meta() *RuleMeta {
+	return r.meta
+}
+
+// FilterMetas returns RuleMeta of FilterFuncs of the rule in order. It has nil for filters that are not described.
+func (r *Rule) FilterMetas() []*RuleMeta {
+	metas := make([]*RuleMeta, len(r.FilterFuncs))
+	copy(metas, r.filterMetas)
+	return metas
+}
@@ -0,0 +1,89 @@
+package formspec
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestRuleMeta(t *testing.T) {
+	s := New()
+
+	examples := []struct {
+		rule     *Rule
+		expected *RuleMeta
+	}{
+		{s.Rule("a", RuleRequired()), &RuleMeta{Name: "required"}},
+		{s.Rule("a", RuleMaxLen(20)), &RuleMeta{Name: "max_len", Params: map[string]interface{}{"max": 20}}},
+		{s.Rule("a", RuleFormat(regexp.MustCompile(`\d+`))), &RuleMeta{Name: "format", Params: map[string]interface{}{"pattern": `\d+`}}},
+		{s.MultiRule("a", RuleMaxItems(3)), &RuleMeta{Name: "max_items", Params: map[string]interface{}{"max": 3}}},
+		{s.FileRule("a", RuleFileMaxSize(1024)), &RuleMeta{Name: "file_max_size", Params: map[string]interface{}{"max": int64(1024)}}},
+		{s.Rule("a", Describe(RuleRequired().Func, "custom", map[string]interface{}{"a": 1})), &RuleMeta{Name: "custom", Params: map[string]interface{}{"a": 1}}},
+		{s.Scenario("").Rules[1], &RuleMeta{Name: "max_len", Params: map[string]interface{}{"max": 20}}},
+	}
+
+	for _, example := range examples {
+		if meta := example.rule.Meta(); !reflect.DeepEqual(meta, example.expected) {
+			t.Errorf("expected meta %+v, but got %+v", example.expected, meta)
+		}
+	}
+}
+
+func TestRuleMeta_NotDescribed(t *testing.T) {
+	called := false
+
+	s := New()
+	s.Rule("name", func(value string, f Form) error {
+		called = true
+		return errors.New("is invalid.")
+	})
+	s.Rule("name", RuleFunc(func(value string, f Form) error {
+		called = true
+		return errors.New("is invalid.")
+	}))
+
+	for _, r := range s.Rules {
+		if meta := r.Meta(); meta != nil {
+			t.Errorf("expected nil meta for not described rule, but got %+v", meta)
+		}
+	}
+
+	if called {
+		t.Error("not described rule must not be called for introspection")
+	}
+}
+
+func TestDescribe_KeepsBehavior(t *testing.T) {
+	s := New()
+	s.Rule("name", Describe(RuleMaxLen(3).Func, "short", nil))
+
+	if r := s.Validate(newDummyform().Set("name", "abcd")); r.Ok {
+		t.Error("expected validation error")
+	}
+
+	if r := s.Validate(newDummyform().Set("name", "abc")); !r.Ok {
+		t.Errorf("validation error is not expected, but got %v", r.Errors)
+	}
+}
+
+func TestRule_PanicsForUnknownFunc(t *testing.T) {
+	examples := []func(s *Formspec){
+		func(s *Formspec) { s.Rule("name", func(value string) error { return nil }) },
+		func(s *Formspec) { s.Rule("tags", RuleMinItems(2)) },
+		func(s *Formspec) { s.MultiRule("tags", RuleRequired()) },
+		func(s *Formspec) { s.Rule("name", RuleRequired()).Filter(RuleRequired()) },
+	}
+
+	for i, example := range examples {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected panic for the value that is not a rule at %d", i)
+				}
+			}()
+
+			example(New())
+		}()
+	}
+}
@@ -0,0 +1,351 @@
+package formspec
+
+import (
+	"context"
+	"mime/multipart"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Mount is a child spec mounted under a prefix of the spec. See Formspec.Mount and Formspec.MountEach.
+type Mount struct {
+	Prefix string
+	Spec   *Formspec
+	// each is true when Spec validates each element of the collection under Prefix.
+	each     bool
+	minItems int
+	maxItems int // no limit when it is negative
+}
+
+// Mount validates the object under the prefix by the child spec.
+// Fields of the child are read in dot notation (`address.street`, See JSONForm) or bracket notation (`address[street]`),
+// and errors have full paths in dot notation as their fields. e.g. "address.street"
+//
+//	address := formspec.New()
+//	address.Rule("street", formspec.RuleRequired())
+//	s.Mount("address", address)
+//
+// Mounted specs are validated after rules of the spec, in mounted order.
+// Labels and templates of the child are used for its errors. Fields that have no labels are labeled by the spec with their full paths.
+func (f *Formspec) Mount(prefix string, child *Formspec) *Mount {
+	mount := &Mount{Prefix: prefix, Spec: child, maxItems: -1}
+	f.mounts = append(f.mounts, mount)
+	return mount
+}
+
+// MountEach validates each element of the indexed collection under the prefix by the child spec.
+// e.g. `addresses[0].street` and `addresses[1][street]` are validated as "street" of the child, and errors have fields like "addresses[1].street".
+// Elements are found by keys of the form (See KeyedForm), or by indexes from 0 while the child has values in them.
+func (f *Formspec) MountEach(prefix string, child *Formspec) *Mount {
+	mount := f.Mount(prefix, child)
+	mount.each = true
+	return mount
+}
+
+// MinItems sets the min number of elements of the collection. It reports the error that has code "min_items" on Prefix.
+func (mt *Mount) MinItems(n int) *Mount {
+	mt.minItems = n
+	return mt
+}
+
+// MaxItems sets the max number of elements of the collection. It reports the error that has code "max_items" on Prefix.
+func (mt *Mount) MaxItems(n int) *Mount {
+	mt.maxItems = n
+	return mt
+}
+
+func (mt *Mount) clone() *Mount {
+	return &Mount{Prefix: mt.Prefix, Spec: mt.Spec.Clone(), each: mt.each, minItems: mt.minItems, maxItems: mt.maxItems}
+}
+
+// paths returns paths of the objects that the child validates.
+func (mt *Mount) paths(form Form) []string {
+	if !mt.each {
+		return []string{mt.Prefix}
+	}
+
+	indexes := mt.indexes(form)
+	paths := make([]string, len(indexes))
+
+	for i, index := range indexes {
+		paths[i] = mt.Prefix + "[" + strconv.Itoa(index) + "]"
+	}
+
+	return paths
+}
+
+// indexes returns indexes of elements of the collection in ascending order.
+func (mt *Mount) indexes(form Form) []int {
+	keys, ok := formKeys(form)
+
+	if !ok {
+		var indexes []int
+
+		for i := 0; mt.Spec.present(newSubForm(form, mt.Prefix+"["+strconv.Itoa(i)+"]")); i++ {
+			indexes = append(indexes, i)
+		}
+
+		return indexes
+	}
+
+	seen := map[int]bool{}
+
+	var indexes []int
+
+	for _, key := range keys {
+		rest, ok := strings.CutPrefix(dotPath(key), mt.Prefix+"[")
+
+		if !ok {
+			continue
+		}
+
+		n, _, _ := strings.Cut(rest, "]")
+
+		if i, err := strconv.Atoi(n); err == nil && i >= 0 && !seen[i] {
+			seen[i] = true
+			indexes = append(indexes, i)
+		}
+	}
+
+	sort.Ints(indexes)
+	return indexes
+}
+
+// check validates objects under the prefix, and adds their errors and values to the result.
+// In partial mode (See Formspec.Partial), the child is partial too, and the collection that is not in the form is not counted.
+func (mt *Mount) check(ctx context.Context, form Form, m *messenger, abort, partial bool, r *Result) *InternalError {
+	paths := mt.paths(form)
+
+	if partial && len(paths) == 0 && !submitted(form, mt.Prefix) {
+		return nil
+	}
+
+	if mt.each && len(paths) < mt.minItems {
+		r.Errors = append(r.Errors, m.fieldError(mt.Prefix, ruleErrorf("min_items", map[string]interface{}{"min": mt.minItems}, RuleMessageMinItems, mt.minItems)))
+	}
+
+	if mt.each && mt.maxItems >= 0 && len(paths) > mt.maxItems {
+		r.Errors = append(r.Errors, m.fieldError(mt.Prefix, ruleErrorf("max_items", map[string]interface{}{"max": mt.maxItems}, RuleMessageMaxItems, mt.maxItems)))
+	}
+
+	// Strict mode of the spec covers fields of the child. See Formspec.known.
+	child := *mt.Spec
+	child.Strict = false
+	child.Partial = child.Partial || partial
+
+	for _, path := range paths {
+		cm := &messenger{
+			labelOf: func(field string) string {
+				if l, ok := child.labels[field]; ok {
+					return l
+				}
+
+				return m.label(joinPath(path, field))
+			},
+			templates: child.templates,
+			catalog:   m.catalog,
+			locale:    m.locale,
+		}
+
+		cr, ierr := child.check(ctx, newSubForm(form, path), cm, abort)
+
+		if ierr != nil {
+			ierr.Field = joinPath(path, ierr.Field)
+			return ierr
+		}
+
+		for _, e := range cr.Errors {
+			e.Field = joinPath(path, e.Field)
+			r.Errors = append(r.Errors, e)
+		}
+
+		for field, values := range cr.Values {
+			r.Values[joinPath(path, field)] = values
+		}
+	}
+
+	return nil
+}
+
+// known returns true if the key (in dot notation) is known by the child. See Formspec.Strict.
+func (mt *Mount) known(key string) bool {
+	rest, ok := strings.CutPrefix(key, mt.Prefix)
+
+	if !ok {
+		return false
+	}
+
+	if mt.each {
+		n, after, ok := strings.Cut(strings.TrimPrefix(rest, "["), "]")
+
+		if _, err := strconv.Atoi(n); !strings.HasPrefix(rest, "[") || !ok || err != nil {
+			return false
+		}
+
+		rest = after
+	}
+
+	field, ok := strings.CutPrefix(rest, ".")
+	return ok && mt.Spec.known(field)
+}
+
+// present returns true if the form has any value of fields of the spec.
+func (f *Formspec) present(form Form) bool {
+	for _, field := range f.fields() {
+		if form.FormValue(field) != "" || len(formValues(form, field)) > 0 || formFile(form, field) != nil {
+			return true
+		}
+	}
+
+	for _, mount := range f.mounts {
+		for _, path := range mount.paths(form) {
+			if mount.Spec.present(newSubForm(form, path)) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// ----------------------------------------------------------------------------
+// subForm
+// ----------------------------------------------------------------------------
+
+// subForm is the Form of the object under the prefix of the root form.
+// Keys are read in dot notation (`prefix.key`), and then in bracket notation (`prefix[key]`).
+type subForm struct {
+	root   Form
+	prefix string
+}
+
+func newSubForm(form Form, prefix string) *subForm {
+	// Nested sub forms read the root form directly.
+	if s, ok := form.(*subForm); ok {
+		return &subForm{root: s.root, prefix: joinPath(s.prefix, prefix)}
+	}
+
+	return &subForm{root: form, prefix: prefix}
+}
+
+func (s *subForm) FormValue(key string) string {
+	path := joinPath(s.prefix, key)
+
+	if v := s.root.FormValue(path); v != "" {
+		return v
+	}
+
+	return s.root.FormValue(bracketPath(path))
+}
+
+func (s *subForm) FormValues(key string) []string {
+	path := joinPath(s.prefix, key)
+
+	if values := formValues(s.root, path); len(values) > 0 {
+		return values
+	}
+
+	return formValues(s.root, bracketPath(path))
+}
+
+func (s *subForm) FormFile(key string) (multipart.File, *multipart.FileHeader, error) {
+	ff, ok := s.root.(FileForm)
+
+	if !ok {
+		return nil, nil, http.ErrMissingFile
+	}
+
+	path := joinPath(s.prefix, key)
+	file, header, err := ff.FormFile(path)
+
+	if err != nil {
+		return ff.FormFile(bracketPath(path))
+	}
+
+	return file, header, err
+}
+
+func (s *subForm) typeError(key string, multi bool) *RuleError {
+	if tf, ok := s.root.(typedForm); ok {
+		return tf.typeError(joinPath(s.prefix, key), multi)
+	}
+
+	return nil
+}
+
+// keys returns keys of the root form under the prefix in dot notation. ok is false when the root form can't list them.
+func (s *subForm) keys() ([]string, bool) {
+	keys, ok := formKeys(s.root)
+
+	if !ok {
+		return nil, false
+	}
+
+	var sub []string
+
+	for _, key := range keys {
+		if rest, ok := strings.CutPrefix(dotPath(key), s.prefix+"."); ok {
+			sub = append(sub, rest)
+		}
+	}
+
+	return sub, true
+}
+
+// joinPath joins the prefix and the key in dot notation.
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+
+	if key == "" {
+		return prefix
+	}
+
+	return prefix + "." + key
+}
+
+// bracketPath converts the path in dot notation to bracket notation. e.g. "addresses[1].geo.lat" -> "addresses[1][geo][lat]"
+func bracketPath(path string) string {
+	parts := strings.Split(path, ".")
+	b := parts[0]
+
+	for _, part := range parts[1:] {
+		name, index, _ := strings.Cut(part, "[")
+		b += "[" + name + "]"
+
+		if index != "" {
+			b += "[" + index
+		}
+	}
+
+	return b
+}
+
+// dotPath converts the path in bracket notation to dot notation. Indexes are kept. e.g. "addresses[1][geo][lat]" -> "addresses[1].geo.lat"
+func dotPath(path string) string {
+	var b strings.Builder
+
+	for {
+		i := strings.IndexByte(path, '[')
+		j := strings.IndexByte(path, ']')
+
+		if i < 0 || j < i {
+			b.WriteString(path)
+			return b.String()
+		}
+
+		b.WriteString(path[:i])
+		name := path[i+1 : j]
+
+		if _, err := strconv.Atoi(name); err == nil {
+			b.WriteString("[" + name + "]")
+		} else {
+			b.WriteString("." + name)
+		}
+
+		path = path[j+1:]
+	}
+}
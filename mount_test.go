@@ -0,0 +1,155 @@
+package formspec
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// -----------------------------------------------------------------------------
+// Test formspec.Mount
+// -----------------------------------------------------------------------------
+
+func newMountTestSpec() *Formspec {
+	address := New()
+	address.Label("street", "Street")
+	address.Rule("street", RuleRequired())
+	address.Rule("zip", RuleInt()).AllowBlank()
+
+	s := New()
+	s.Rule("name", RuleRequired())
+	s.MountEach("addresses", address).MinItems(1).MaxItems(2)
+	return s
+}
+
+func testMountErrors(t *testing.T, r *Result, expected [][2]string) {
+	if len(r.Errors) != len(expected) {
+		t.Fatalf("expected %d errors, but got %v", len(expected), r.Errors)
+	}
+
+	for i, e := range expected {
+		if r.Errors[i].Field != e[0] || r.Errors[i].Message != e[1] {
+			t.Errorf("expected error (%s, %s), but got (%s, %s)", e[0], e[1], r.Errors[i].Field, r.Errors[i].Message)
+		}
+	}
+}
+
+func TestMountEach(t *testing.T) {
+	s := newMountTestSpec()
+
+	// Bracket notation
+	r := s.Validate(Values(url.Values{
+		"name":                  {"toqoz"},
+		"addresses[0][street]":  {"Main St."},
+		"addresses[0][zip]":     {"100"},
+		"addresses[10][street]": {""},
+		"addresses[10][zip]":    {"x"},
+	}))
+
+	testMountErrors(t, r, [][2]string{
+		{"addresses[10].street", "Street is required."},
+		{"addresses[10].zip", "addresses[10].zip must be integer."},
+	})
+
+	if r.Value("addresses[0].street") != "Main St." {
+		t.Errorf("expected value of `addresses[0].street`, but got %v", r.Values)
+	}
+
+	// Dot notation
+	f, err := NewJSONForm([]byte(`{"addresses": [{"street": "Main St."}, {"zip": 1}, {"street": "x"}]}`))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testMountErrors(t, s.ValidateLocale(f, "ja"), [][2]string{
+		{"name", "nameを入力してください。"},
+		{"addresses", "addressesは2個以下で選択してください。"},
+		{"addresses[1].street", "Streetを入力してください。"},
+	})
+
+	// Min items
+	testMountErrors(t, s.Validate(Values(url.Values{"name": {"toqoz"}})), [][2]string{
+		{"addresses", "addresses must have at least 1 items."},
+	})
+
+	// Forms that can't list their keys are read from index 0 while the child has values.
+	r = s.Validate(newDummyform().Set("name", "toqoz").Set("addresses[0].street", "a").Set("addresses[1].zip", "x").Set("addresses[3].zip", "x"))
+
+	testMountErrors(t, r, [][2]string{
+		{"addresses[1].street", "Street is required."},
+		{"addresses[1].zip", "addresses[1].zip must be integer."},
+	})
+}
+
+func TestMount(t *testing.T) {
+	geo := New()
+	geo.Rule("lat", RuleNumber())
+
+	address := New()
+	address.Rule("street", RuleRequired())
+	address.Mount("geo", geo)
+
+	person := New()
+	person.Rule("name", RuleRequired())
+	person.MountEach("addresses", address)
+
+	s := New()
+	s.Strict = true
+	s.Mount("owner", person)
+
+	r := s.Validate(Values(url.Values{
+		"owner[name]":                    {""},
+		"owner[addresses][0][street]":    {"Main St."},
+		"owner[addresses][0][geo][lat]":  {"x"},
+		"owner[addresses][0][geo][evil]": {"1"},
+		"owner[evil]":                    {"1"},
+	}))
+
+	testMountErrors(t, r, [][2]string{
+		{"owner.name", "owner.name is required."},
+		{"owner.addresses[0].geo.lat", "owner.addresses[0].geo.lat must be number."},
+		{"owner[addresses][0][geo][evil]", "owner[addresses][0][geo][evil] is not allowed."},
+		{"owner[evil]", "owner[evil] is not allowed."},
+	})
+
+	// Mounted specs are exported as nested objects.
+	j, err := json.Marshal(s.JSONSchema().Properties["owner"].Properties["addresses"])
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"type":"array","items":{"type":"object","properties":{"geo":{"type":"object","properties":{"lat":{"type":"number"}}},"street":{"type":"string"}},"required":["street"]}}`
+
+	if string(j) != expected {
+		t.Errorf("expected\n%s\nbut got\n%s", expected, j)
+	}
+}
+
+func TestMount_InternalError(t *testing.T) {
+	user := New()
+	user.ContextRule("email", testEmailNotTaken)
+
+	s := New()
+	s.MountEach("users", user)
+
+	_, err := s.ValidateContext(context.Background(), Values(url.Values{"users[0][email]": {"new@example.com"}, "users[1][email]": {"down@example.com"}}))
+
+	var ierr *InternalError
+
+	if !errors.As(err, &ierr) || ierr.Field != "users[1].email" {
+		t.Errorf("expected InternalError of `users[1].email`, but got %v", err)
+	}
+
+	// Mounts are cloned.
+	clone := s.Clone()
+	user.Rule("name", RuleRequired())
+
+	if r := clone.Validate(Values(url.Values{"users[0][email]": {"new@example.com"}})); !r.Ok || !reflect.DeepEqual(r.Values, Values{"users[0].email": {"new@example.com"}}) {
+		t.Errorf("expected the clone not to be changed, but got %v", r.Errors)
+	}
+}
@@ -0,0 +1,91 @@
+package formspec
+
+// OpenAPIParameter is a Parameter Object of OpenAPI 3.1.
+type OpenAPIParameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// OpenAPIRequestBody is a Request Body Object of OpenAPI 3.1.
+type OpenAPIRequestBody struct {
+	Required bool                         `json:"required,omitempty"`
+	Content  map[string]*OpenAPIMediaType `json:"content"`
+}
+
+// OpenAPIMediaType is a Media Type Object of OpenAPI 3.1.
+type OpenAPIMediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// OpenAPIParameters exports the fields as OpenAPI 3.1 parameters in the location. e.g. "query", "header"
+// Parameters are in the order that fields appear in Formspec.Rules. Their schemas are same as Formspec.JSONSchema,
+// and messages overridden by Rule.FullMessage/Rule.Message go into `description` of the parameter.
+func (f *Formspec) OpenAPIParameters(in string) []*OpenAPIParameter {
+	s := f.objectSchema()
+	required := map[string]bool{}
+
+	for _, field := range s.Required {
+		required[field] = true
+	}
+
+	var params []*OpenAPIParameter
+
+	for _, field := range f.fields() {
+		prop, ok := s.Properties[field]
+
+		// The name of group rules is not a parameter.
+		if !ok {
+			continue
+		}
+
+		params = append(params, &OpenAPIParameter{
+			Name:        field,
+			In:          in,
+			Description: prop.Description,
+			Required:    required[field],
+			Schema:      prop,
+		})
+
+		prop.Description = ""
+	}
+
+	return params
+}
+
+// OpenAPIRequestBody exports the spec as OpenAPI 3.1 request body of `application/x-www-form-urlencoded`.
+// It is `multipart/form-data` when the spec has file rules.
+func (f *Formspec) OpenAPIRequestBody() *OpenAPIRequestBody {
+	s := f.objectSchema()
+	contentType := "application/x-www-form-urlencoded"
+
+	for _, rule := range f.Rules {
+		if rule.FileRuleFunc != nil {
+			contentType = "multipart/form-data"
+			break
+		}
+	}
+
+	return &OpenAPIRequestBody{
+		Required: len(s.Required) > 0,
+		Content:  map[string]*OpenAPIMediaType{contentType: {Schema: s}},
+	}
+}
+
+// fields returns names of fields in the order that they appear in Formspec.Rules.
+func (f *Formspec) fields() []string {
+	var fields []string
+
+	seen := map[string]bool{}
+
+	for _, rule := range f.Rules {
+		if !seen[rule.Field] {
+			seen[rule.Field] = true
+			fields = append(fields, rule.Field)
+		}
+	}
+
+	return fields
+}
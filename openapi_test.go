@@ -0,0 +1,53 @@
+package formspec
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestOpenAPIParameters(t *testing.T) {
+	s := New()
+	s.Rule("q", RuleRequired())
+	s.Rule("q", RuleMaxLen(100)).Message("must be at most 100 characters.")
+	s.Rule("page", RuleIntGreaterThan(0)).AllowBlank()
+	s.MultiRule("tags", RuleMaxItems(3))
+
+	j, err := json.Marshal(s.OpenAPIParameters("query"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `[` +
+		`{"name":"q","in":"query","description":"q must be at most 100 characters.","required":true,"schema":{"type":"string","maxLength":100}},` +
+		`{"name":"page","in":"query","schema":{"type":"integer","exclusiveMinimum":0}},` +
+		`{"name":"tags","in":"query","schema":{"type":"array","items":{"type":"string"},"maxItems":3}}]`
+
+	if string(j) != expected {
+		t.Errorf("expected\n%s\nbut got\n%s", expected, j)
+	}
+}
+
+func TestOpenAPIRequestBody(t *testing.T) {
+	s := New()
+	s.Rule("name", RuleRequired()).FullMessage("Please enter your name.")
+
+	j, err := json.Marshal(s.OpenAPIRequestBody())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"required":true,"content":{"application/x-www-form-urlencoded":{"schema":` +
+		`{"type":"object","properties":{"name":{"type":"string","description":"Please enter your name."}},"required":["name"]}}}}`
+
+	if string(j) != expected {
+		t.Errorf("expected\n%s\nbut got\n%s", expected, j)
+	}
+
+	s.FileRule("avatar", RuleFileMaxSize(1024))
+
+	if _, ok := s.OpenAPIRequestBody().Content["multipart/form-data"]; !ok {
+		t.Error("expected multipart/form-data for the spec that has file rules")
+	}
+}
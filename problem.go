@@ -0,0 +1,107 @@
+package formspec
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// ProblemType is the `type` of problem details for validation errors. See Problem.
+var ProblemType = "about:blank"
+
+// Problem is the problem details (RFC 9457) of validation errors. It is rendered as `application/problem+json`.
+//
+//	{
+//	  "type": "about:blank",
+//	  "title": "Unprocessable Entity",
+//	  "status": 422,
+//	  "detail": "The request has invalid parameters.",
+//	  "invalid-params": [
+//	    {"name": "nick", "reason": "nick is too long. Max is 3 character.", "code": "max_len", "params": {"max": 3}}
+//	  ]
+//	}
+type Problem struct {
+	Type          string          `json:"type"`
+	Title         string          `json:"title"`
+	Status        int             `json:"status"`
+	Detail        string          `json:"detail,omitempty"`
+	InvalidParams []*InvalidParam `json:"invalid-params"`
+	// Code and Params are of the error that has no field, that is told by Detail. e.g. "body_too_large" (See Handler)
+	Code   string                 `json:"code,omitempty"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+// problemDetail is the detail of NewProblem for results that have only errors of fields.
+const problemDetail = "The request has invalid parameters."
+
+// InvalidParam is an item of `invalid-params` of Problem. It is made from *Error.
+type InvalidParam struct {
+	Name   string                 `json:"name"`
+	Reason string                 `json:"reason"`
+	Code   string                 `json:"code,omitempty"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+// NewProblem returns the problem details of the result for the status. e.g. http.StatusUnprocessableEntity
+// The error that has no field (e.g. the body is too large. See Handler) is told by `detail`, `code` and `params` instead of `invalid-params`.
+func NewProblem(r *Result, status int) *Problem {
+	p := &Problem{
+		Type:          ProblemType,
+		Title:         http.StatusText(status),
+		Status:        status,
+		InvalidParams: []*InvalidParam{},
+	}
+
+	if status < http.StatusInternalServerError {
+		p.Detail = problemDetail
+	}
+
+	told := false
+
+	for _, e := range r.Errors {
+		if e.Field == "" && !told {
+			p.Detail, p.Code, p.Params = e.Message, e.Code, e.Params
+			told = true
+			continue
+		}
+
+		p.InvalidParams = append(p.InvalidParams, &InvalidParam{Name: e.Field, Reason: e.Message, Code: e.Code, Params: e.Params})
+	}
+
+	return p
+}
+
+// Result returns the Result that has errors of invalid params. Values of the Result are nil.
+// Problem details always tell a failure, so the Result is not ok even if it has no errors.
+// Detail is the error that has no field, unless it is the detail of NewProblem for errors of fields.
+func (p *Problem) Result() *Result {
+	r := NewOkResult()
+	r.Ok = false
+
+	if p.Detail != "" && p.Detail != problemDetail {
+		r.Errors = append(r.Errors, &Error{Message: p.Detail, Code: p.Code, Params: p.Params})
+	}
+
+	for _, param := range p.InvalidParams {
+		r.Errors = append(r.Errors, &Error{Field: param.Name, Message: param.Reason, Code: param.Code, Params: param.Params})
+	}
+
+	return r
+}
+
+// ParseProblem parses the problem details rendered by NewProblem (or other services) into the Result.
+// Numbers in params are float64 as encoding/json decodes them.
+//
+//	if resp.StatusCode == http.StatusUnprocessableEntity {
+//		body, _ := io.ReadAll(resp.Body)
+//		result, err := formspec.ParseProblem(body)
+//	}
+func ParseProblem(data []byte) (*Result, error) {
+	p := &Problem{}
+
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("formspec: bad problem details: %v", err)
+	}
+
+	return p.Result(), nil
+}
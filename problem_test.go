@@ -0,0 +1,86 @@
+package formspec
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// -----------------------------------------------------------------------------
+// Test formspec.Problem
+// -----------------------------------------------------------------------------
+
+func TestProblem(t *testing.T) {
+	s := New()
+	s.Rule("name", RuleRequired())
+	s.Rule("nick", RuleMaxLen(3))
+
+	r := s.Validate(Values(url.Values{"nick": {"toqoz"}}))
+
+	j, err := json.Marshal(NewProblem(r, 422))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"type":"about:blank","title":"Unprocessable Entity","status":422,"detail":"The request has invalid parameters.",` +
+		`"invalid-params":[` +
+		`{"name":"name","reason":"name is required.","code":"required"},` +
+		`{"name":"nick","reason":"nick is too long. Max is 3 character.","code":"max_len","params":{"max":3}}]}`
+
+	if string(j) != expected {
+		t.Errorf("expected\n%s\nbut got\n%s", expected, j)
+	}
+
+	parsed, err := ParseProblem(j)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Numbers are decoded as float64.
+	r.Errors[1].Params = map[string]interface{}{"max": float64(3)}
+	r.Values = nil
+
+	if !reflect.DeepEqual(parsed, r) {
+		t.Errorf("expected %+v, but got %+v", r, parsed)
+	}
+}
+
+func TestParseProblem(t *testing.T) {
+	// Problem details from other services that have no codes.
+	r, err := ParseProblem([]byte(`{"type":"https://example.com/probs/invalid","title":"Bad","invalid-params":[{"name":"age","reason":"must be a positive integer"}],"instance":"/users"}`))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &Result{Errors: []*Error{{Field: "age", Message: "must be a positive integer"}}}
+
+	if !reflect.DeepEqual(r, expected) {
+		t.Errorf("expected %+v, but got %+v", expected, r)
+	}
+
+	// Problem details are failures even if they have no invalid params.
+	if r, err := ParseProblem([]byte(`{"type":"about:blank","status":422}`)); err != nil || r.Ok || len(r.Errors) != 0 {
+		t.Errorf("expected failed result without errors, but got (%v, %v)", r, err)
+	}
+
+	// Detail is the error that has no field.
+	r, err = ParseProblem([]byte(`{"type":"about:blank","status":400,"detail":"The request body is broken."}`))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = &Result{Errors: []*Error{{Message: "The request body is broken."}}}
+
+	if !reflect.DeepEqual(r, expected) {
+		t.Errorf("expected %+v, but got %+v", expected, r)
+	}
+
+	if _, err := ParseProblem([]byte(`<html>`)); err == nil {
+		t.Error("expected error for bad problem details, but got nil")
+	}
+}
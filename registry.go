@@ -0,0 +1,476 @@
+package formspec
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// RuleBuilder builds the rule from parameters in rule strings and struct tags.
+// e.g. "maxlen:20" -> RuleBuilder for "maxlen" is called with []string{"20"}
+// Use Describe to build DescribedRule, so that rules built by it are described by the name.
+type RuleBuilder func(args []string) (DescribedRule, error)
+
+// MultiRuleBuilder builds the multi-value rule from parameters in rule strings and struct tags. See RuleBuilder.
+type MultiRuleBuilder func(args []string) (DescribedMultiRule, error)
+
+// FilterBuilder builds the filter from parameters in filter strings. e.g. "truncate:20"
+// Use DescribeFilter to build DescribedFilter. See RuleBuilder.
+type FilterBuilder func(args []string) (DescribedFilter, error)
+
+// Registry holds named rules and filters that are used by rule strings (See Registry.Parse),
+// struct tags (See NewFromStruct) and spec files (See LoadFile).
+type Registry struct {
+	rules      map[string]RuleBuilder
+	multiRules map[string]MultiRuleBuilder
+	filters    map[string]FilterBuilder
+}
+
+// DefaultRegistry is used by ParseRules, Formspec.RuleString and NewFromStruct.
+// It has built-in rules, and you can add your own rules by RegisterRule/RegisterMultiRule.
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry returns a Registry that has built-in rules.
+//
+//	required, maxlen:N, minlen:N, format:REGEXP, in:A,B,..., number, int,
+//	floatlt:N, floatgt:N, intlt:N, intgt:N,
+//	eqfield:FIELD, nefield:FIELD, ltfield:FIELD, gtfield:FIELD, beforefield:FIELD,LAYOUT, afterfield:FIELD,LAYOUT,
+//	atleastoneof:FIELD,..., exactlyoneof:FIELD,..., mutuallyexclusive:FIELD,... (RuleFunc)
+//	minitems:N, maxitems:N, unique (MultiRuleFunc)
+//	trim, trimclass:CLASS,..., lower, upper, collapsespace, stripcontrol, foldwidth, nfc, nfkc, striptags, truncate:N (FilterFunc)
+func NewRegistry() *Registry {
+	r := &Registry{
+		rules:      map[string]RuleBuilder{},
+		multiRules: map[string]MultiRuleBuilder{},
+		filters:    map[string]FilterBuilder{},
+	}
+
+	r.Register("required", func(args []string) (DescribedRule, error) {
+		return RuleRequired(), checkArgs(args, 0)
+	})
+	r.Register("maxlen", func(args []string) (DescribedRule, error) {
+		n, err := intArg(args)
+		return RuleMaxLen(n), err
+	})
+	r.Register("minlen", func(args []string) (DescribedRule, error) {
+		n, err := intArg(args)
+		return RuleMinLen(n), err
+	})
+	r.Register("format", func(args []string) (DescribedRule, error) {
+		if len(args) == 0 {
+			return DescribedRule{}, checkArgs(args, 1)
+		}
+
+		// Regexp can contain `,`. So all parameters are joined.
+		re, err := regexp.Compile(strings.Join(args, ","))
+
+		if err != nil {
+			return DescribedRule{}, err
+		}
+
+		return RuleFormat(re), nil
+	})
+	r.Register("in", func(args []string) (DescribedRule, error) {
+		if len(args) == 0 {
+			return DescribedRule{}, fmt.Errorf("expected 1 or more parameter(s), but got 0")
+		}
+
+		return RuleIn(args...), nil
+	})
+	r.Register("number", func(args []string) (DescribedRule, error) {
+		return RuleNumber(), checkArgs(args, 0)
+	})
+	r.Register("int", func(args []string) (DescribedRule, error) {
+		return RuleInt(), checkArgs(args, 0)
+	})
+	r.Register("floatlt", func(args []string) (DescribedRule, error) {
+		a, err := floatArg(args)
+		return RuleFloatLessThan(a), err
+	})
+	r.Register("floatgt", func(args []string) (DescribedRule, error) {
+		a, err := floatArg(args)
+		return RuleFloatGreaterThan(a), err
+	})
+	r.Register("intlt", func(args []string) (DescribedRule, error) {
+		a, err := floatArg(args)
+		return RuleIntLessThan(a), err
+	})
+	r.Register("intgt", func(args []string) (DescribedRule, error) {
+		a, err := intArg(args)
+		return RuleIntGreaterThan(a), err
+	})
+
+	r.Register("eqfield", func(args []string) (DescribedRule, error) {
+		return RuleEqualToField(strings.Join(args, "")), checkArgs(args, 1)
+	})
+	r.Register("nefield", func(args []string) (DescribedRule, error) {
+		return RuleNotEqualToField(strings.Join(args, "")), checkArgs(args, 1)
+	})
+	r.Register("ltfield", func(args []string) (DescribedRule, error) {
+		return RuleLessThanField(strings.Join(args, "")), checkArgs(args, 1)
+	})
+	r.Register("gtfield", func(args []string) (DescribedRule, error) {
+		return RuleGreaterThanField(strings.Join(args, "")), checkArgs(args, 1)
+	})
+	r.Register("beforefield", func(args []string) (DescribedRule, error) {
+		if err := checkArgs(args, 2); err != nil {
+			return DescribedRule{}, err
+		}
+
+		return RuleBeforeField(args[0], args[1]), nil
+	})
+	r.Register("afterfield", func(args []string) (DescribedRule, error) {
+		if err := checkArgs(args, 2); err != nil {
+			return DescribedRule{}, err
+		}
+
+		return RuleAfterField(args[0], args[1]), nil
+	})
+
+	r.Register("atleastoneof", func(args []string) (DescribedRule, error) {
+		return RuleAtLeastOneOf(args...), nil
+	})
+	r.Register("exactlyoneof", func(args []string) (DescribedRule, error) {
+		return RuleExactlyOneOf(args...), nil
+	})
+	r.Register("mutuallyexclusive", func(args []string) (DescribedRule, error) {
+		return RuleMutuallyExclusive(args...), nil
+	})
+
+	r.RegisterMulti("minitems", func(args []string) (DescribedMultiRule, error) {
+		n, err := intArg(args)
+		return RuleMinItems(n), err
+	})
+	r.RegisterMulti("maxitems", func(args []string) (DescribedMultiRule, error) {
+		n, err := intArg(args)
+		return RuleMaxItems(n), err
+	})
+	r.RegisterMulti("unique", func(args []string) (DescribedMultiRule, error) {
+		return RuleUniqueItems(), checkArgs(args, 0)
+	})
+
+	r.RegisterFilter("trim", FilterTrim())
+	r.RegisterFilterBuilder("trimclass", func(args []string) (DescribedFilter, error) {
+		if len(args) == 0 {
+			return DescribedFilter{}, fmt.Errorf("expected unicode classes")
+		}
+
+		if _, err := rangeTables(args); err != nil {
+			return DescribedFilter{}, err
+		}
+
+		return FilterTrimClass(args...), nil
+	})
+	r.RegisterFilter("lower", FilterLower())
+	r.RegisterFilter("upper", FilterUpper())
+	r.RegisterFilter("collapsespace", FilterCollapseSpace())
+	r.RegisterFilter("stripcontrol", FilterStripControl())
+	r.RegisterFilter("foldwidth", FilterFoldWidth())
+	r.RegisterFilter("nfc", FilterNFC())
+	r.RegisterFilter("nfkc", FilterNFKC())
+	r.RegisterFilter("striptags", FilterStripTags())
+	r.RegisterFilterBuilder("truncate", func(args []string) (DescribedFilter, error) {
+		n, err := intArg(args)
+		return FilterTruncate(n), err
+	})
+
+	return r
+}
+
+// Register adds the rule. The rule that has same name is overridden.
+func (r *Registry) Register(name string, b RuleBuilder) {
+	delete(r.multiRules, name)
+	r.rules[name] = b
+}
+
+// RegisterMulti adds the multi-value rule. The rule that has same name is overridden.
+func (r *Registry) RegisterMulti(name string, b MultiRuleBuilder) {
+	delete(r.rules, name)
+	r.multiRules[name] = b
+}
+
+// RegisterFilter adds the filter that has no parameters. The filter that has same name is overridden.
+// filterFunc is FilterFunc or DescribedFilter (See Rule.Filter).
+func (r *Registry) RegisterFilter(name string, filterFunc interface{}) {
+	fn, meta := filterFuncOf(filterFunc)
+
+	r.RegisterFilterBuilder(name, func(args []string) (DescribedFilter, error) {
+		return DescribedFilter{Func: fn, Meta: meta}, checkArgs(args, 0)
+	})
+}
+
+// RegisterFilterBuilder adds the filter that has parameters. The filter that has same name is overridden.
+func (r *Registry) RegisterFilterBuilder(name string, b FilterBuilder) {
+	r.filters[name] = b
+}
+
+// RegisterRule adds the rule to DefaultRegistry.
+func RegisterRule(name string, b RuleBuilder) {
+	DefaultRegistry.Register(name, b)
+}
+
+// RegisterMultiRule adds the multi-value rule to DefaultRegistry.
+func RegisterMultiRule(name string, b MultiRuleBuilder) {
+	DefaultRegistry.RegisterMulti(name, b)
+}
+
+// RegisterFilter adds the filter to DefaultRegistry.
+func RegisterFilter(name string, filterFunc interface{}) {
+	DefaultRegistry.RegisterFilter(name, filterFunc)
+}
+
+// RegisterFilterBuilder adds the filter that has parameters to DefaultRegistry.
+func RegisterFilterBuilder(name string, b FilterBuilder) {
+	DefaultRegistry.RegisterFilterBuilder(name, b)
+}
+
+func checkArgs(args []string, n int) error {
+	if len(args) != n {
+		return fmt.Errorf("expected %d parameter(s), but got %d", n, len(args))
+	}
+
+	return nil
+}
+
+func intArg(args []string) (int, error) {
+	if err := checkArgs(args, 1); err != nil {
+		return 0, err
+	}
+
+	return strconv.Atoi(args[0])
+}
+
+func floatArg(args []string) (float64, error) {
+	if err := checkArgs(args, 1); err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseFloat(args[0], 64)
+}
+
+// ----------------------------------------------------------------------------
+// Rule strings
+// ----------------------------------------------------------------------------
+
+// ParseError is returned when a rule string can't be parsed.
+type ParseError struct {
+	Input  string
+	Column int // 1-based column (byte) of the problem
+	Msg    string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("formspec: %s at column %d in %q", e.Msg, e.Column, e.Input)
+}
+
+// ruleToken is a rule in rule strings. e.g. "maxlen:20"
+type ruleToken struct {
+	name   string
+	args   []string
+	column int
+}
+
+// tokenizeRules splits the rule string like `required|maxlen:20|in:a,b,c`.
+// Rules are separated by `|`, and parameters follow `:` and are separated by `,`.
+// Parameters quoted with `'` can contain `|` and `,`.
+func tokenizeRules(s string) ([]ruleToken, error) {
+	var tokens []ruleToken
+
+	for i := 0; i <= len(s); {
+		tok := ruleToken{column: i + 1}
+
+		for i < len(s) && isRuleNameChar(s[i]) {
+			tok.name += string(s[i])
+			i++
+		}
+
+		if tok.name == "" {
+			return nil, &ParseError{Input: s, Column: i + 1, Msg: "expected rule name"}
+		}
+
+		if i < len(s) && s[i] == ':' {
+			i++
+
+			for {
+				arg, next, err := scanRuleArg(s, i)
+
+				if err != nil {
+					return nil, err
+				}
+
+				tok.args = append(tok.args, arg)
+				i = next
+
+				if i < len(s) && s[i] == ',' {
+					i++
+					continue
+				}
+
+				break
+			}
+		}
+
+		if i < len(s) && s[i] != '|' {
+			return nil, &ParseError{Input: s, Column: i + 1, Msg: fmt.Sprintf("unexpected %q", s[i])}
+		}
+
+		tokens = append(tokens, tok)
+		i++
+	}
+
+	return tokens, nil
+}
+
+func scanRuleArg(s string, i int) (string, int, error) {
+	if i < len(s) && s[i] == '\'' {
+		end := strings.IndexByte(s[i+1:], '\'')
+
+		if end < 0 {
+			return "", 0, &ParseError{Input: s, Column: i + 1, Msg: "unterminated quote"}
+		}
+
+		return s[i+1 : i+1+end], i + end + 2, nil
+	}
+
+	start := i
+
+	for i < len(s) && s[i] != ',' && s[i] != '|' {
+		i++
+	}
+
+	return s[start:i], i, nil
+}
+
+func isRuleNameChar(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
+
+// Parse parses the rule string like `required|maxlen:20|in:a,b,c` into rules.
+func (r *Registry) Parse(s string) ([]DescribedRule, error) {
+	tokens, err := tokenizeRules(s)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ruleFuncs := make([]DescribedRule, 0, len(tokens))
+
+	for _, tok := range tokens {
+		ruleFunc, err := r.buildRule(s, tok)
+
+		if err != nil {
+			return nil, err
+		}
+
+		ruleFuncs = append(ruleFuncs, ruleFunc)
+	}
+
+	return ruleFuncs, nil
+}
+
+func (r *Registry) buildRule(s string, tok ruleToken) (DescribedRule, error) {
+	b, ok := r.rules[tok.name]
+
+	if !ok {
+		if _, ok := r.multiRules[tok.name]; ok {
+			return DescribedRule{}, &ParseError{Input: s, Column: tok.column, Msg: fmt.Sprintf("%s is a multi-value rule", tok.name)}
+		}
+
+		return DescribedRule{}, &ParseError{Input: s, Column: tok.column, Msg: fmt.Sprintf("unknown rule %q", tok.name)}
+	}
+
+	ruleFunc, err := b(tok.args)
+
+	if err != nil {
+		return DescribedRule{}, &ParseError{Input: s, Column: tok.column, Msg: fmt.Sprintf("bad parameter for %s: %v", tok.name, err)}
+	}
+
+	return ruleFunc, nil
+}
+
+// ParseFilters parses the filter string like `trim|lower|truncate:20` into filters.
+func (r *Registry) ParseFilters(s string) ([]DescribedFilter, error) {
+	tokens, err := tokenizeRules(s)
+
+	if err != nil {
+		return nil, err
+	}
+
+	filterFuncs := make([]DescribedFilter, 0, len(tokens))
+
+	for _, tok := range tokens {
+		b, ok := r.filters[tok.name]
+
+		if !ok {
+			return nil, &ParseError{Input: s, Column: tok.column, Msg: fmt.Sprintf("unknown filter %q", tok.name)}
+		}
+
+		filterFunc, err := b(tok.args)
+
+		if err != nil {
+			return nil, &ParseError{Input: s, Column: tok.column, Msg: fmt.Sprintf("bad parameter for %s: %v", tok.name, err)}
+		}
+
+		filterFuncs = append(filterFuncs, filterFunc)
+	}
+
+	return filterFuncs, nil
+}
+
+// ParseRules parses the rule string by DefaultRegistry.
+func ParseRules(s string) ([]DescribedRule, error) {
+	return DefaultRegistry.Parse(s)
+}
+
+// RuleString adds rules written in the rule string to the field by DefaultRegistry.
+// In addition to rules, it accepts `allowblank` that is applied to all rules of the string.
+//
+//	s.RuleString("name", "required|maxlen:20")
+//	s.RuleString("tags", "maxitems:5|unique|allowblank")
+func (f *Formspec) RuleString(field, s string) ([]*Rule, error) {
+	tokens, err := tokenizeRules(s)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		rules      []*Rule
+		allowBlank bool
+	)
+
+	for _, tok := range tokens {
+		if tok.name == "allowblank" && len(tok.args) == 0 {
+			allowBlank = true
+			continue
+		}
+
+		if b, ok := DefaultRegistry.multiRules[tok.name]; ok {
+			multiRuleFunc, err := b(tok.args)
+
+			if err != nil {
+				return nil, &ParseError{Input: s, Column: tok.column, Msg: fmt.Sprintf("bad parameter for %s: %v", tok.name, err)}
+			}
+
+			rules = append(rules, &Rule{Field: field, MultiRuleFunc: multiRuleFunc.Func, meta: multiRuleFunc.Meta})
+			continue
+		}
+
+		ruleFunc, err := DefaultRegistry.buildRule(s, tok)
+
+		if err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, &Rule{Field: field, RuleFunc: ruleFunc.Func, meta: ruleFunc.Meta})
+	}
+
+	for _, rule := range rules {
+		rule.allowBlank = allowBlank
+	}
+
+	// Rules are added only when whole of the string is valid.
+	f.Rules = append(f.Rules, rules...)
+	return rules, nil
+}
@@ -0,0 +1,150 @@
+package formspec
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestParseRules(t *testing.T) {
+	ruleFuncs, err := ParseRules("required|maxlen:5|in:a,bb,'c|d'")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ruleFuncs) != 3 {
+		t.Fatalf("expected 3 rules, but got %d", len(ruleFuncs))
+	}
+
+	s := New()
+
+	for _, ruleFunc := range ruleFuncs {
+		s.Rule("name", ruleFunc)
+	}
+
+	examples := []ruleTestExample{
+		{"a", true}, {"bb", true}, {"c|d", true},
+		{"", false}, {"c", false}, {"aaaaaa", false},
+	}
+
+	for _, example := range examples {
+		if r := s.Validate(newDummyform().Set("name", example.input)); r.Ok != example.expected {
+			t.Errorf("Test ParseRules: When `%s` is given, expected result is (_, %v). But got (_, %v).", example.input, example.expected, r.Ok)
+		}
+	}
+}
+
+func TestParseRules_Format(t *testing.T) {
+	ruleFuncs, err := ParseRules(`format:\A[a-z]{1,3}\z`)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := New()
+	s.Rule("name", ruleFuncs[0])
+
+	examples := []ruleTestExample{{"abc", true}, {"abcd", false}, {"ABC", false}}
+
+	for _, example := range examples {
+		if r := s.Validate(newDummyform().Set("name", example.input)); r.Ok != example.expected {
+			t.Errorf("Test ParseRules(format): When `%s` is given, expected result is (_, %v). But got (_, %v).", example.input, example.expected, r.Ok)
+		}
+	}
+}
+
+func TestParseRules_Errors(t *testing.T) {
+	examples := []struct {
+		input  string
+		column int
+	}{
+		{"", 1},
+		{"required|", 10},
+		{"required||int", 10},
+		{"required|unknown", 10},
+		{"required|maxlen:x", 10},
+		{"maxlen:1|minlen", 10},
+		{"in:'a", 4},
+		{"int|maxitems:2", 5},
+		{"int;required", 4},
+	}
+
+	for _, example := range examples {
+		_, err := ParseRules(example.input)
+
+		perr, ok := err.(*ParseError)
+
+		if !ok {
+			t.Errorf("Test ParseRules: When `%s` is given, expected *ParseError, but got %v", example.input, err)
+			continue
+		}
+
+		if perr.Column != example.column {
+			t.Errorf("Test ParseRules: When `%s` is given, expected error at column %d, but got %v", example.input, example.column, perr)
+		}
+	}
+}
+
+func TestRegisterRule(t *testing.T) {
+	r := NewRegistry()
+	r.Register("even", func(args []string) (DescribedRule, error) {
+		return Describe(func(value string, f Form) error {
+			if len(value)%2 != 0 {
+				return errors.New("must have even length.")
+			}
+
+			return nil
+		}, "even", nil), checkArgs(args, 0)
+	})
+
+	ruleFuncs, err := r.Parse("required|even")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := New()
+	s.Rule("name", ruleFuncs[1])
+
+	if meta := s.Rules[0].Meta(); meta == nil || meta.Name != "even" {
+		t.Errorf("expected meta of the registered rule, but got %+v", meta)
+	}
+
+	if res := s.Validate(newDummyform().Set("name", "abc")); res.Ok || res.Errors[0].Message != "name must have even length." {
+		t.Errorf("expected error `name must have even length.`, but got %v", res.Errors)
+	}
+
+	// DefaultRegistry is not changed.
+	if _, err := ParseRules("even"); err == nil {
+		t.Error("expected error for the rule that is not registered to DefaultRegistry")
+	}
+}
+
+func TestRuleString(t *testing.T) {
+	s := New()
+
+	if _, err := s.RuleString("age", "int|intgt:0|allowblank"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.RuleString("tags", "maxitems:2|unique"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.RuleString("name", "required|unknown"); err == nil {
+		t.Fatal("expected error for unknown rule")
+	}
+
+	if len(s.Rules) != 4 {
+		t.Fatalf("expected 4 rules, but got %d", len(s.Rules))
+	}
+
+	if r := s.Validate(Values(url.Values{})); !r.Ok {
+		t.Errorf("validation error is not expected, but got %v", r.Errors)
+	}
+
+	if r := s.Validate(Values(url.Values{"age": {"0"}, "tags": {"a", "a", "b"}})); len(r.Errors) != 3 {
+		t.Errorf("expected 3 errors, but got %v", r.Errors)
+	}
+}
@@ -1,8 +1,6 @@
 package formspec
 
 import (
-	"errors"
-	"fmt"
 	"regexp"
 	"strconv"
 	"unicode/utf8"
@@ -24,76 +22,93 @@ var (
 	RuleInvalidMessage     = "is invalid."
 	RuleMessageNumber      = "must be number."
 	RuleMessageInt         = "must be integer."
-	RuleMessageLessThan    = "must be less than %d"
-	RuleMessageGreaterThan = "must be greater than %d"
+	RuleMessageLessThan    = "must be less than %v"
+	RuleMessageGreaterThan = "must be greater than %v"
+	RuleMessageIn          = "is not included in the list."
+	RuleMessageMinItems    = "must have at least %d items."
+	RuleMessageMaxItems    = "must have at most %d items."
+	RuleMessageUniqueItems = "must not have duplicate items. Item %d is a duplicate."
+	RuleMessageEach        = "item %d %s"
 )
 
 // funcs that return RuleFunc
 // They must have prefix `Rule`.
 
-func RuleRequired() RuleFunc {
-	return func(value string, _ Form) error {
+func RuleRequired() DescribedRule {
+	return Describe(func(value string, _ Form) error {
 		if value == "" {
-			return errors.New(RuleMessageRequired)
+			return ruleErrorf("required", nil, RuleMessageRequired)
 		}
 
 		return nil
-	}
+	}, "required", nil)
 }
 
-func RuleMaxLen(maxLen int) RuleFunc {
-	return func(value string, _ Form) error {
+func RuleMaxLen(maxLen int) DescribedRule {
+	return Describe(func(value string, _ Form) error {
 		if utf8.RuneCountInString(value) > maxLen {
-			return fmt.Errorf(RuleMessageMaxLen, maxLen)
+			return ruleErrorf("max_len", map[string]interface{}{"max": maxLen}, RuleMessageMaxLen, maxLen)
 		}
 
 		return nil
-	}
+	}, "max_len", map[string]interface{}{"max": maxLen})
 }
 
-func RuleMinLen(minLen int) RuleFunc {
-	return func(value string, _ Form) error {
+func RuleMinLen(minLen int) DescribedRule {
+	return Describe(func(value string, _ Form) error {
 		if utf8.RuneCountInString(value) < minLen {
-			return fmt.Errorf(RuleMessageMinLen, minLen)
+			return ruleErrorf("min_len", map[string]interface{}{"min": minLen}, RuleMessageMinLen, minLen)
 		}
 
 		return nil
-	}
+	}, "min_len", map[string]interface{}{"min": minLen})
 }
 
-func RuleFormat(r *regexp.Regexp) RuleFunc {
-	return func(value string, _ Form) error {
+func RuleFormat(r *regexp.Regexp) DescribedRule {
+	return Describe(func(value string, _ Form) error {
 		if !r.MatchString(value) {
-			return errors.New(RuleInvalidMessage)
+			return ruleErrorf("format", map[string]interface{}{"pattern": r.String()}, RuleInvalidMessage)
 		}
 
 		return nil
-	}
+	}, "format", map[string]interface{}{"pattern": r.String()})
 }
 
-func RuleNumber() RuleFunc {
-	return func(value string, _ Form) error {
+func RuleIn(values ...string) DescribedRule {
+	return Describe(func(value string, _ Form) error {
+		for _, v := range values {
+			if v == value {
+				return nil
+			}
+		}
+
+		return ruleErrorf("in", map[string]interface{}{"values": values}, RuleMessageIn)
+	}, "in", map[string]interface{}{"values": values})
+}
+
+func RuleNumber() DescribedRule {
+	return Describe(func(value string, _ Form) error {
 		if !RuleFormatNumber.MatchString(value) {
-			return errors.New(RuleMessageNumber)
+			return ruleErrorf("number", nil, RuleMessageNumber)
 		}
 
 		return nil
-	}
+	}, "number", nil)
 }
 
-func RuleInt() RuleFunc {
-	return func(value string, _ Form) error {
+func RuleInt() DescribedRule {
+	return Describe(func(value string, _ Form) error {
 		if !RuleFormatInt.MatchString(value) {
-			return errors.New(RuleMessageInt)
+			return ruleErrorf("int", nil, RuleMessageInt)
 		}
 
 		return nil
-	}
+	}, "int", nil)
 }
 
-func RuleFloatLessThan(a float64) RuleFunc {
-	return func(value string, f Form) error {
-		err := RuleNumber()(value, f)
+func RuleFloatLessThan(a float64) DescribedRule {
+	return Describe(func(value string, f Form) error {
+		err := RuleNumber().Func(value, f)
 
 		if err != nil {
 			return err
@@ -102,20 +117,20 @@ func RuleFloatLessThan(a float64) RuleFunc {
 		i, err := strconv.ParseFloat(value, 64)
 
 		if err != nil {
-			return errors.New(RuleMessageNumber)
+			return ruleErrorf("number", nil, RuleMessageNumber)
 		}
 
 		if !(i < a) {
-			return fmt.Errorf(RuleMessageLessThan, a)
+			return ruleErrorf("float_less_than", map[string]interface{}{"value": a}, RuleMessageLessThan, a)
 		}
 
 		return nil
-	}
+	}, "float_less_than", map[string]interface{}{"value": a})
 }
 
-func RuleFloatGreaterThan(a float64) RuleFunc {
-	return func(value string, f Form) error {
-		err := RuleNumber()(value, f)
+func RuleFloatGreaterThan(a float64) DescribedRule {
+	return Describe(func(value string, f Form) error {
+		err := RuleNumber().Func(value, f)
 
 		if err != nil {
 			return err
@@ -124,20 +139,20 @@ func RuleFloatGreaterThan(a float64) RuleFunc {
 		i, err := strconv.ParseFloat(value, 64)
 
 		if err != nil {
-			return errors.New(RuleMessageNumber)
+			return ruleErrorf("number", nil, RuleMessageNumber)
 		}
 
 		if !(i > a) {
-			return fmt.Errorf(RuleMessageGreaterThan, a)
+			return ruleErrorf("float_greater_than", map[string]interface{}{"value": a}, RuleMessageGreaterThan, a)
 		}
 
 		return nil
-	}
+	}, "float_greater_than", map[string]interface{}{"value": a})
 }
 
-func RuleIntLessThan(a float64) RuleFunc {
-	return func(value string, f Form) error {
-		err := RuleInt()(value, f)
+func RuleIntLessThan(a float64) DescribedRule {
+	return Describe(func(value string, f Form) error {
+		err := RuleInt().Func(value, f)
 
 		if err != nil {
 			return err
@@ -146,20 +161,20 @@ func RuleIntLessThan(a float64) RuleFunc {
 		i, err := strconv.ParseFloat(value, 64)
 
 		if err != nil {
-			return errors.New(RuleMessageInt)
+			return ruleErrorf("int", nil, RuleMessageInt)
 		}
 
 		if !(i < a) {
-			return fmt.Errorf(RuleMessageLessThan, a)
+			return ruleErrorf("int_less_than", map[string]interface{}{"value": a}, RuleMessageLessThan, a)
 		}
 
 		return nil
-	}
+	}, "int_less_than", map[string]interface{}{"value": a})
 }
 
-func RuleIntGreaterThan(a int) RuleFunc {
-	return func(value string, f Form) error {
-		err := RuleInt()(value, f)
+func RuleIntGreaterThan(a int) DescribedRule {
+	return Describe(func(value string, f Form) error {
+		err := RuleInt().Func(value, f)
 
 		if err != nil {
 			return err
@@ -168,13 +183,85 @@ func RuleIntGreaterThan(a int) RuleFunc {
 		i, err := strconv.Atoi(value)
 
 		if err != nil {
-			return errors.New(RuleMessageInt)
+			return ruleErrorf("int", nil, RuleMessageInt)
 		}
 
 		if !(i > a) {
-			return fmt.Errorf(RuleMessageGreaterThan, a)
+			return ruleErrorf("int_greater_than", map[string]interface{}{"value": a}, RuleMessageGreaterThan, a)
 		}
 
 		return nil
+	}, "int_greater_than", map[string]interface{}{"value": a})
+}
+
+// funcs that return MultiRuleFunc
+// They must have prefix `Rule` too.
+
+func RuleMinItems(minItems int) DescribedMultiRule {
+	return DescribeMulti(func(values []string, _ Form) error {
+		if len(values) < minItems {
+			return ruleErrorf("min_items", map[string]interface{}{"min": minItems}, RuleMessageMinItems, minItems)
+		}
+
+		return nil
+	}, "min_items", map[string]interface{}{"min": minItems})
+}
+
+func RuleMaxItems(maxItems int) DescribedMultiRule {
+	return DescribeMulti(func(values []string, _ Form) error {
+		if len(values) > maxItems {
+			return ruleErrorf("max_items", map[string]interface{}{"max": maxItems}, RuleMessageMaxItems, maxItems)
+		}
+
+		return nil
+	}, "max_items", map[string]interface{}{"max": maxItems})
+}
+
+func RuleUniqueItems() DescribedMultiRule {
+	return DescribeMulti(func(values []string, _ Form) error {
+		seen := map[string]bool{}
+
+		for i, v := range values {
+			if seen[v] {
+				return ruleErrorf("unique_items", map[string]interface{}{"index": i}, RuleMessageUniqueItems, i)
+			}
+
+			seen[v] = true
+		}
+
+		return nil
+	}, "unique_items", nil)
+}
+
+// RuleEach applies ruleFunc to every item. The error tells the index (0-based) of the first item that failed.
+// ruleFunc is RuleFunc or DescribedRule.
+func RuleEach(each interface{}) DescribedMultiRule {
+	ruleFunc, meta := ruleFuncOf(each)
+
+	return DescribeMulti(func(values []string, f Form) error {
+		for i, v := range values {
+			if err := ruleFunc(v, f); err != nil {
+				return eachError(i, err)
+			}
+		}
+
+		return nil
+	}, "each", map[string]interface{}{"rule": meta})
+}
+
+// eachError returns *RuleError for the item that failed in RuleEach.
+// It has the code and params of the error for the item, and "index" param.
+func eachError(index int, err error) *RuleError {
+	rerr := ruleErrorf("invalid", map[string]interface{}{"index": index}, RuleMessageEach, index, err.Error())
+	rerr.item = true
+
+	if e, ok := err.(*RuleError); ok {
+		rerr.Code, rerr.refFields = e.Code, e.refFields
+
+		for k, v := range e.Params {
+			rerr.Params[k] = v
+		}
 	}
+
+	return rerr
 }
@@ -1,6 +1,7 @@
 package formspec
 
 import (
+	"net/url"
 	"regexp"
 	"testing"
 )
@@ -29,6 +30,11 @@ type ruleTestExample struct {
 	expected bool
 }
 
+type multiRuleTestExample struct {
+	input    []string
+	expected bool
+}
+
 // -----------------------------------------------------------------------------
 // Test formspec.RuleRequired
 // -----------------------------------------------------------------------------
@@ -177,6 +183,29 @@ func TestRuleNumber(t *testing.T) {
 
 var ()
 
+// -----------------------------------------------------------------------------
+// Test formspec.RuleIn
+// -----------------------------------------------------------------------------
+
+func TestRuleIn(t *testing.T) {
+	s := New()
+	s.Rule("color", RuleIn("red", "green"))
+
+	examples := []ruleTestExample{
+		{"red", true}, {"green", true},
+		{"blue", false}, {"", false}, {"Red", false},
+	}
+
+	for _, example := range examples {
+		f := newDummyform()
+		f.Set("color", example.input)
+
+		if r := s.Validate(f); r.Ok != example.expected {
+			t.Errorf("Test RuleIn(\"red\", \"green\"): When `%s` is given, expected result is (_, %v). But got (_, %v).", example.input, example.expected, r.Ok)
+		}
+	}
+}
+
 // -----------------------------------------------------------------------------
 // Test formspec.RuleIntGreaterThan
 // -----------------------------------------------------------------------------
@@ -300,3 +329,89 @@ func TestRuleFloatLessThan(t *testing.T) {
 		}
 	}
 }
+
+// -----------------------------------------------------------------------------
+// Test formspec.RuleMinItems
+// -----------------------------------------------------------------------------
+
+func TestRuleMinItems(t *testing.T) {
+	s := New()
+	s.MultiRule("tags", RuleMinItems(2))
+
+	examples := []multiRuleTestExample{
+		{[]string{}, false}, {[]string{"a"}, false},
+		{[]string{"a", "b"}, true}, {[]string{"a", "b", "c"}, true},
+	}
+
+	for _, example := range examples {
+		if r := s.Validate(Values(url.Values{"tags": example.input})); r.Ok != example.expected {
+			t.Errorf("Test RuleMinItems(2): When `%v` is given, expected result is (_, %v). But got (_, %v).", example.input, example.expected, r.Ok)
+		}
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Test formspec.RuleMaxItems
+// -----------------------------------------------------------------------------
+
+func TestRuleMaxItems(t *testing.T) {
+	s := New()
+	s.MultiRule("tags", RuleMaxItems(2))
+
+	examples := []multiRuleTestExample{
+		{[]string{}, true}, {[]string{"a"}, true}, {[]string{"a", "b"}, true},
+		{[]string{"a", "b", "c"}, false},
+	}
+
+	for _, example := range examples {
+		if r := s.Validate(Values(url.Values{"tags": example.input})); r.Ok != example.expected {
+			t.Errorf("Test RuleMaxItems(2): When `%v` is given, expected result is (_, %v). But got (_, %v).", example.input, example.expected, r.Ok)
+		}
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Test formspec.RuleUniqueItems
+// -----------------------------------------------------------------------------
+
+func TestRuleUniqueItems(t *testing.T) {
+	s := New()
+	s.MultiRule("tags", RuleUniqueItems())
+
+	examples := []multiRuleTestExample{
+		{[]string{}, true}, {[]string{"a", "b"}, true},
+		{[]string{"a", "a"}, false}, {[]string{"a", "b", "a"}, false},
+	}
+
+	for _, example := range examples {
+		if r := s.Validate(Values(url.Values{"tags": example.input})); r.Ok != example.expected {
+			t.Errorf("Test RuleUniqueItems(): When `%v` is given, expected result is (_, %v). But got (_, %v).", example.input, example.expected, r.Ok)
+		}
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Test formspec.RuleEach
+// -----------------------------------------------------------------------------
+
+func TestRuleEach(t *testing.T) {
+	s := New()
+	s.MultiRule("ids", RuleEach(RuleInt()))
+
+	examples := []multiRuleTestExample{
+		{[]string{}, true}, {[]string{"1", "2"}, true},
+		{[]string{"1", "x"}, false}, {[]string{"x"}, false},
+	}
+
+	for _, example := range examples {
+		if r := s.Validate(Values(url.Values{"ids": example.input})); r.Ok != example.expected {
+			t.Errorf("Test RuleEach(RuleInt()): When `%v` is given, expected result is (_, %v). But got (_, %v).", example.input, example.expected, r.Ok)
+		}
+	}
+
+	r := s.Validate(Values(url.Values{"ids": {"1", "x"}}))
+
+	if r.Ok || r.Errors[0].Message != "ids item 1 must be integer." {
+		t.Errorf("Test RuleEach(RuleInt()): expected error `ids item 1 must be integer.`, but got %v", r.Errors)
+	}
+}
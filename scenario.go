@@ -0,0 +1,158 @@
+package formspec
+
+import (
+	"strings"
+)
+
+// On tags the rule with the scenarios. e.g. "create", "update"
+// The rule is applied only when the spec is validated in one of them (See Formspec.Scenario).
+// Rules that have no scenarios are applied in every scenario.
+//
+//	s.Rule("password", formspec.RuleRequired()).On("create")
+//	s.Rule("password", formspec.RuleMinLen(8)).AllowBlank()
+func (r *Rule) On(scenarios ...string) *Rule {
+	r.scenarios = append(r.scenarios, scenarios...)
+	return r
+}
+
+// Scenarios returns the scenarios given by Rule.On.
+func (r *Rule) Scenarios() []string {
+	return r.scenarios
+}
+
+// in returns true if the rule is applied in the scenario. "" is the scenario of specs that are not made by Formspec.Scenario.
+func (r *Rule) in(scenario string) bool {
+	if len(r.scenarios) == 0 {
+		return true
+	}
+
+	for _, s := range r.scenarios {
+		if s == scenario {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Scenario returns the clone of the spec that has the rules for the scenario, that are rules tagged with it and rules without tags.
+// Specs mounted on the spec are in the scenario too. Rules tagged with scenarios are not applied by the spec itself.
+//
+//	create := s.Scenario("create")
+//	update := s.Scenario("update")
+//	update.Partial = true
+func (f *Formspec) Scenario(name string) *Formspec {
+	clone := f.Clone()
+	clone.scenario = name
+	clone.Rules = nil
+
+	for _, rule := range f.Rules {
+		if rule.in(name) {
+			clone.Rules = append(clone.Rules, rule.clone())
+		}
+	}
+
+	for _, mount := range clone.mounts {
+		mount.Spec = mount.Spec.Scenario(name)
+	}
+
+	return clone
+}
+
+// Only returns the clone of the spec that has only rules of the fields. Fields may be names of groups or prefixes of mounted specs.
+//
+//	profile := s.Only("name", "nick")
+func (f *Formspec) Only(fields ...string) *Formspec {
+	return f.pick(fields, true)
+}
+
+// Except returns the clone of the spec that has rules except ones of the fields. See Formspec.Only.
+//
+//	admin := s.Except("captcha")
+func (f *Formspec) Except(fields ...string) *Formspec {
+	return f.pick(fields, false)
+}
+
+func (f *Formspec) pick(fields []string, only bool) *Formspec {
+	picked := map[string]bool{}
+
+	for _, field := range fields {
+		picked[field] = true
+	}
+
+	clone := f.Clone()
+	clone.Rules = nil
+	clone.mounts = nil
+
+	for _, rule := range f.Rules {
+		if picked[rule.Field] == only {
+			clone.Rules = append(clone.Rules, rule.clone())
+		}
+	}
+
+	for _, mount := range f.mounts {
+		if picked[mount.Prefix] == only {
+			clone.mounts = append(clone.mounts, mount.clone())
+		}
+	}
+
+	return clone
+}
+
+// active returns the spec that has rules to be applied to the form.
+// They are rules in the scenario of the spec, and in partial mode, rules of the fields that are in the form (See Formspec.Partial).
+// It returns the spec itself if all rules are applied.
+func (f *Formspec) active(form Form) *Formspec {
+	var rules []*Rule
+
+	for _, rule := range f.Rules {
+		if !rule.in(f.scenario) || f.Partial && !rule.submitted(form) {
+			continue
+		}
+
+		rules = append(rules, rule)
+	}
+
+	if len(rules) == len(f.Rules) {
+		return f
+	}
+
+	a := *f
+	a.Rules = rules
+	return &a
+}
+
+// submitted returns true if the field of the rule is in the form. Group rules are submitted when any field of the group is in it.
+func (r *Rule) submitted(form Form) bool {
+	if group := r.GroupFields(); group != nil {
+		for _, field := range group {
+			if submitted(form, field) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return submitted(form, r.Field)
+}
+
+// submitted returns true if the form has the field or fields under it as a prefix (e.g. `address.street` for "address"), even if they are blank.
+// Forms that can't list their keys (See KeyedForm) have the field when it has values or a file.
+func submitted(form Form, field string) bool {
+	keys, ok := formKeys(form)
+
+	if !ok {
+		return form.FormValue(field) != "" || len(formValues(form, field)) > 0 || formFile(form, field) != nil
+	}
+
+	for _, key := range keys {
+		key = dotPath(key)
+
+		if key == field || strings.HasPrefix(key, field+".") || strings.HasPrefix(key, field+"[") {
+			return true
+		}
+	}
+
+	return false
+}
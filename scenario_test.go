@@ -0,0 +1,242 @@
+package formspec
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func newScenarioTestSpec() *Formspec {
+	s := New()
+	s.Rule("name", RuleRequired())
+	s.Rule("name", RuleMaxLen(5))
+	s.Rule("password", RuleRequired()).On("create")
+	s.Rule("password", RuleMinLen(4)).AllowBlank()
+	s.Rule("reason", RuleRequired()).On("update", "delete")
+	s.AtLeastOneOf("contact", "email", "phone").On("create", "update")
+	return s
+}
+
+func testScenarioErrors(t *testing.T, r *Result, expected []string) {
+	if len(r.Errors) != len(expected) {
+		t.Fatalf("expected %d errors, but got %v", len(expected), r.Errors)
+	}
+
+	for i, e := range expected {
+		if r.Errors[i].Message != e {
+			t.Errorf("expected error `%s`, but got `%s`", e, r.Errors[i].Message)
+		}
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Test formspec.Scenario
+// -----------------------------------------------------------------------------
+
+func TestScenario(t *testing.T) {
+	s := newScenarioTestSpec()
+	f := Values(url.Values{})
+
+	testScenarioErrors(t, s.Scenario("create").Validate(f), []string{
+		"name is required.",
+		"password is required.",
+		"contact requires at least one of email, phone.",
+	})
+
+	testScenarioErrors(t, s.Scenario("update").Validate(f), []string{
+		"name is required.",
+		"reason is required.",
+		"contact requires at least one of email, phone.",
+	})
+
+	testScenarioErrors(t, s.Scenario("delete").Validate(f), []string{
+		"name is required.",
+		"reason is required.",
+	})
+
+	// Rules tagged with scenarios are not applied by the spec itself.
+	testScenarioErrors(t, s.Validate(f), []string{
+		"name is required.",
+	})
+
+	if len(s.Scenario("delete").Rules) != 4 || len(s.Rules) != 6 {
+		t.Errorf("expected rules of the scenario in the clone, but got %d rules", len(s.Scenario("delete").Rules))
+	}
+
+	if !reflect.DeepEqual(s.Rules[4].Scenarios(), []string{"update", "delete"}) {
+		t.Errorf("expected scenarios of the rule, but got %v", s.Rules[4].Scenarios())
+	}
+
+	if _, ok := s.JSONSchema().Properties["reason"]; ok {
+		t.Error("expected rules of other scenarios not to be exported")
+	}
+
+	// Mounted specs are in the scenario too.
+	parent := New()
+	parent.Mount("user", s)
+
+	testScenarioErrors(t, parent.Scenario("delete").Validate(f), []string{
+		"user.name is required.",
+		"user.reason is required.",
+	})
+}
+
+func TestScenario_Clone(t *testing.T) {
+	s := New()
+	s.Rule("password", RuleRequired()).On("create").On("update").On("reset")
+
+	a, b := s.Scenario("create"), s.Only("password")
+	a.Rules[0].On("invite")
+	b.Rules[0].On("import")
+
+	if scenarios := a.Rules[0].Scenarios(); !reflect.DeepEqual(scenarios, []string{"create", "update", "reset", "invite"}) {
+		t.Errorf("expected scenarios of the clone, but got %v", scenarios)
+	}
+
+	if scenarios := b.Rules[0].Scenarios(); !reflect.DeepEqual(scenarios, []string{"create", "update", "reset", "import"}) {
+		t.Errorf("expected scenarios of the clone, but got %v", scenarios)
+	}
+
+	if scenarios := s.Rules[0].Scenarios(); len(scenarios) != 3 {
+		t.Errorf("expected scenarios of the original not to be changed, but got %v", scenarios)
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Test formspec.Partial
+// -----------------------------------------------------------------------------
+
+func TestPartial(t *testing.T) {
+	s := newScenarioTestSpec().Scenario("update")
+	s.Partial = true
+
+	// Missing fields are not validated.
+	if r := s.Validate(Values(url.Values{"reason": {"typo"}})); !r.Ok || !reflect.DeepEqual(r.Values, Values{"reason": {"typo"}}) {
+		t.Errorf("expected only `reason` to be validated, but got %v %v", r.Errors, r.Values)
+	}
+
+	// Fields in the form are validated even if they are blank.
+	testScenarioErrors(t, s.Validate(Values(url.Values{"name": {""}, "password": {"abc"}, "phone": {""}})), []string{
+		"name is required.",
+		"password is too short. Min is 4 character.",
+		"contact requires at least one of email, phone.",
+	})
+
+	// null of JSON is a blank value in the form.
+	f, err := NewJSONForm([]byte(`{"name": null}`))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testScenarioErrors(t, s.Validate(f), []string{
+		"name is required.",
+	})
+
+	// Forms that can't list their keys have fields that have values.
+	testScenarioErrors(t, s.Validate(newDummyform().Set("name", "toqoz403")), []string{
+		"name is too long. Max is 5 character.",
+	})
+}
+
+func TestPartial_Mount(t *testing.T) {
+	address := New()
+	address.Rule("street", RuleRequired())
+	address.Rule("zip", RuleRequired())
+
+	s := New()
+	s.Partial = true
+	s.Mount("home", address)
+	s.MountEach("addresses", address).MinItems(1)
+
+	if r := s.Validate(Values(url.Values{})); !r.Ok {
+		t.Errorf("validation error is not expected, but got %v", r.Errors)
+	}
+
+	f, err := NewJSONForm([]byte(`{"home": {"zip": ""}, "addresses": []}`))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testScenarioErrors(t, s.Validate(f), []string{
+		"home.zip is required.",
+		"addresses must have at least 1 items.",
+	})
+}
+
+// -----------------------------------------------------------------------------
+// Test formspec.Only/formspec.Except
+// -----------------------------------------------------------------------------
+
+func TestOnlyExcept(t *testing.T) {
+	s := New()
+	s.Rule("name", RuleRequired())
+	s.Rule("nick", RuleRequired())
+	s.AtLeastOneOf("contact", "email", "phone")
+	s.Mount("address", New().Label("street", "Street"))
+	s.Label("nick", "Nick")
+
+	only := s.Only("nick", "contact")
+
+	testScenarioErrors(t, only.Validate(Values(url.Values{})), []string{
+		"Nick is required.",
+		"contact requires at least one of email, phone.",
+	})
+
+	except := s.Except("nick", "contact", "address")
+
+	testScenarioErrors(t, except.Validate(Values(url.Values{})), []string{
+		"name is required.",
+	})
+
+	if len(only.mounts) != 0 || len(except.mounts) != 0 || len(s.Only("address").mounts) != 1 || len(s.Rules) != 3 {
+		t.Error("expected Only/Except to pick rules and mounts of the clone")
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Test scenarios in spec files and struct tags
+// -----------------------------------------------------------------------------
+
+func TestScenario_Load(t *testing.T) {
+	s, err := ParseYAML([]byte(`partial: true
+fields:
+  - field: name
+    rules: [required]
+  - field: password
+    on: create
+    rules:
+      - required
+      - rule: minlen
+        params: [4]
+        on: [create, update]
+`))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !s.Partial || !reflect.DeepEqual(s.Rules[2].Scenarios(), []string{"create", "update"}) {
+		t.Errorf("expected the partial spec with scenarios, but got %v %v", s.Partial, s.Rules[2].Scenarios())
+	}
+
+	testScenarioErrors(t, s.Scenario("create").Validate(Values(url.Values{"password": {""}})), []string{
+		"password is required.",
+		"password is too short. Min is 4 character.",
+	})
+
+	type user struct {
+		Password string `formspec:"password,required,on=create|update"`
+	}
+
+	s, err = NewFromStruct(&user{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(s.Rules[0].Scenarios(), []string{"create", "update"}) {
+		t.Errorf("expected scenarios of the tag, but got %v", s.Rules[0].Scenarios())
+	}
+}
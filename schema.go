@@ -0,0 +1,328 @@
+package formspec
+
+import (
+	"reflect"
+	"sort"
+)
+
+// JSONSchemaDraft is the value of `$schema` in documents exported by Formspec.JSONSchema.
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema. It has only keywords that rules are mapped to.
+type Schema struct {
+	Schema           string             `json:"$schema,omitempty"`
+	Type             string             `json:"type,omitempty"`
+	Description      string             `json:"description,omitempty"`
+	Properties       map[string]*Schema `json:"properties,omitempty"`
+	Required         []string           `json:"required,omitempty"`
+	Items            *Schema            `json:"items,omitempty"`
+	MinLength        *int               `json:"minLength,omitempty"`
+	MaxLength        *int               `json:"maxLength,omitempty"`
+	Pattern          string             `json:"pattern,omitempty"`
+	Enum             []string           `json:"enum,omitempty"`
+	ExclusiveMinimum *float64           `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum *float64           `json:"exclusiveMaximum,omitempty"`
+	MinItems         *int               `json:"minItems,omitempty"`
+	MaxItems         *int               `json:"maxItems,omitempty"`
+	UniqueItems      bool               `json:"uniqueItems,omitempty"`
+	ContentMediaType string             `json:"contentMediaType,omitempty"`
+	Const            *string            `json:"const,omitempty"`
+	AllOf            []*Schema          `json:"allOf,omitempty"`
+	AnyOf            []*Schema          `json:"anyOf,omitempty"`
+	OneOf            []*Schema          `json:"oneOf,omitempty"`
+	If               *Schema            `json:"if,omitempty"`
+	Then             *Schema            `json:"then,omitempty"`
+	Not              *Schema            `json:"not,omitempty"`
+}
+
+// JSONSchema exports the spec as JSON Schema (draft 2020-12) of an object that has the fields as properties.
+// Rules that are not described (See Describe) are not exported, and messages overridden by Rule.FullMessage/Rule.Message go into `description`.
+//
+//	required -> required
+//	max_len, min_len -> maxLength, minLength
+//	format -> pattern (Note that RE2 syntax like `\A` and `\z` is not translated)
+//	in -> enum
+//	number, int -> type: number, integer
+//	*_less_than, *_greater_than -> exclusiveMaximum, exclusiveMinimum
+//	min_items, max_items, unique_items, each -> type: array, minItems, maxItems, uniqueItems, items
+//
+// Rules that have conditions (See Rule.When) are exported as `if`/`then` in `allOf`,
+// and group rules (See Formspec.AtLeastOneOf) are exported as `anyOf`, `oneOf` and `not` in `allOf`.
+func (f *Formspec) JSONSchema() *Schema {
+	s := f.objectSchema()
+	s.Schema = JSONSchemaDraft
+	return s
+}
+
+func (f *Formspec) objectSchema() *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+
+	for _, rule := range f.Rules {
+		if !rule.in(f.scenario) {
+			continue
+		}
+
+		group := rule.GroupFields()
+
+		// The field of group rule may be the name of the group.
+		if _, ok := s.Properties[rule.Field]; !ok && group == nil {
+			s.Properties[rule.Field] = &Schema{Type: "string"}
+		}
+
+		if len(rule.conditions) == 0 {
+			applyRule(s, rule)
+			continue
+		}
+
+		// Conditional rule is exported as `if` the conditions `then` the rule.
+		then := &Schema{Properties: map[string]*Schema{}}
+
+		if group == nil {
+			then.Properties[rule.Field] = &Schema{}
+		}
+
+		applyRule(then, rule)
+
+		if reflect.DeepEqual(then.Properties[rule.Field], &Schema{}) {
+			delete(then.Properties, rule.Field)
+		}
+
+		if len(then.Properties) == 0 {
+			then.Properties = nil
+		}
+
+		s.AllOf = append(s.AllOf, &Schema{If: conditionsSchema(rule.conditions), Then: then})
+	}
+
+	// Mounted specs are exported as nested objects, or arrays of them.
+	for _, mount := range f.mounts {
+		child := mount.Spec.objectSchema()
+
+		if !mount.each {
+			s.Properties[mount.Prefix] = child
+			continue
+		}
+
+		items := &Schema{Type: "array", Items: child}
+
+		if n := mount.minItems; n > 0 {
+			items.MinItems = &n
+		}
+
+		if n := mount.maxItems; n >= 0 {
+			items.MaxItems = &n
+		}
+
+		s.Properties[mount.Prefix] = items
+	}
+
+	return s
+}
+
+// applyRule applies the rule to the property of the object schema.
+func applyRule(s *Schema, rule *Rule) {
+	meta := rule.Meta()
+
+	if fields := rule.GroupFields(); fields != nil {
+		applyGroupMeta(s, meta.Name, fields)
+		return
+	}
+
+	prop := s.Properties[rule.Field]
+
+	if m := rule.customMessage(); m != "" {
+		if prop.Description != "" {
+			prop.Description += "\n"
+		}
+
+		prop.Description += m
+	}
+
+	if meta == nil {
+		return
+	}
+
+	if meta.Name == "required" || meta.Name == "file_required" {
+		if !rule.allowBlank {
+			s.addRequired(rule.Field)
+		}
+
+		return
+	}
+
+	if rule.MultiRuleFunc != nil {
+		if prop.Type != "array" {
+			// Constraints for single value that are already applied move to items.
+			items := *prop
+			*prop = Schema{Type: "array", Items: &items}
+		}
+
+		applyMultiMeta(prop, meta)
+		return
+	}
+
+	applyMeta(prop, meta)
+}
+
+// applyGroupMeta applies the group rule to the object schema.
+// Note that a field is treated as present when it exists even if it is blank.
+func applyGroupMeta(s *Schema, name string, fields []string) {
+	var present []*Schema
+
+	for _, field := range fields {
+		present = append(present, &Schema{Required: []string{field}})
+	}
+
+	switch name {
+	case "at_least_one_of":
+		s.AllOf = append(s.AllOf, &Schema{AnyOf: present})
+	case "exactly_one_of":
+		s.AllOf = append(s.AllOf, &Schema{OneOf: present})
+	case "mutually_exclusive":
+		for i := range fields {
+			for _, other := range fields[i+1:] {
+				s.AllOf = append(s.AllOf, &Schema{Not: &Schema{Required: []string{fields[i], other}}})
+			}
+		}
+	}
+}
+
+func (s *Schema) addRequired(field string) {
+	for _, f := range s.Required {
+		if f == field {
+			return
+		}
+	}
+
+	s.Required = append(s.Required, field)
+	sort.Strings(s.Required)
+}
+
+func conditionsSchema(conditions []*Condition) *Schema {
+	if len(conditions) == 1 {
+		return conditionSchema(conditions[0])
+	}
+
+	s := &Schema{}
+
+	for _, c := range conditions {
+		s.AllOf = append(s.AllOf, conditionSchema(c))
+	}
+
+	return s
+}
+
+func conditionSchema(c *Condition) *Schema {
+	prop := &Schema{}
+
+	switch c.Op {
+	case ConditionEquals:
+		v := c.Value
+		prop.Const = &v
+	case ConditionMatches:
+		prop.Pattern = c.Value
+	case ConditionPresent, ConditionAbsent:
+		one := 1
+		prop.MinLength = &one
+	}
+
+	s := &Schema{Properties: map[string]*Schema{c.Field: prop}, Required: []string{c.Field}}
+
+	if (c.Op == ConditionAbsent) != c.Negated {
+		return &Schema{Not: s}
+	}
+
+	return s
+}
+
+// customMessage returns the error message overridden by Rule.FullMessage/Rule.Message.
+func (r *Rule) customMessage() string {
+	if r.fullMessage != "" {
+		return r.fullMessage
+	}
+
+	if r.message != "" {
+		return r.Field + " " + r.message
+	}
+
+	return ""
+}
+
+func applyMeta(s *Schema, meta *RuleMeta) {
+	// A field that has multi-value rules is array. Rules for single value apply to its items.
+	if s.Type == "array" {
+		s = s.Items
+	}
+
+	switch meta.Name {
+	case "max_len":
+		s.MaxLength = intParam(meta, "max")
+	case "min_len":
+		s.MinLength = intParam(meta, "min")
+	case "format":
+		s.Pattern, _ = meta.Params["pattern"].(string)
+	case "in":
+		s.Enum, _ = meta.Params["values"].([]string)
+	case "number":
+		s.Type = "number"
+	case "int":
+		s.Type = "integer"
+	case "float_less_than", "int_less_than":
+		s.Type = numberType(meta.Name)
+		s.ExclusiveMaximum = floatParam(meta, "value")
+	case "float_greater_than", "int_greater_than":
+		s.Type = numberType(meta.Name)
+		s.ExclusiveMinimum = floatParam(meta, "value")
+	case "file_mime_type":
+		if types, _ := meta.Params["types"].([]string); len(types) == 1 {
+			s.ContentMediaType = types[0]
+		}
+	}
+}
+
+func applyMultiMeta(s *Schema, meta *RuleMeta) {
+	switch meta.Name {
+	case "min_items":
+		s.MinItems = intParam(meta, "min")
+	case "max_items":
+		s.MaxItems = intParam(meta, "max")
+	case "unique_items":
+		s.UniqueItems = true
+	case "each":
+		if m, _ := meta.Params["rule"].(*RuleMeta); m != nil {
+			applyMeta(s.Items, m)
+		}
+	}
+}
+
+func numberType(name string) string {
+	if name == "int_less_than" || name == "int_greater_than" {
+		return "integer"
+	}
+
+	return "number"
+}
+
+func intParam(meta *RuleMeta, key string) *int {
+	switch v := meta.Params[key].(type) {
+	case int:
+		return &v
+	case int64:
+		i := int(v)
+		return &i
+	}
+
+	return nil
+}
+
+func floatParam(meta *RuleMeta, key string) *float64 {
+	switch v := meta.Params[key].(type) {
+	case float64:
+		return &v
+	case int:
+		f := float64(v)
+		return &f
+	}
+
+	return nil
+}
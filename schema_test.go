@@ -0,0 +1,45 @@
+package formspec
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+)
+
+func TestJSONSchema(t *testing.T) {
+	s := New()
+	s.Rule("name", RuleRequired())
+	s.Rule("name", RuleMaxLen(20))
+	s.Rule("name", RuleMinLen(2))
+	s.Rule("code", RuleFormat(regexp.MustCompile(`^[a-z]+$`)))
+	s.Rule("color", RuleIn("red", "green")).AllowBlank()
+	s.Rule("age", RuleIntGreaterThan(0))
+	s.Rule("age", RuleIntLessThan(150))
+	s.Rule("rate", RuleFloatLessThan(1.5))
+	s.Rule("nick", RuleRequired()).AllowBlank()
+	s.MultiRule("tags", RuleMaxItems(3))
+	s.MultiRule("tags", RuleUniqueItems())
+	s.MultiRule("tags", RuleEach(RuleMaxLen(10)))
+	s.Rule("custom", func(value string, f Form) error { return nil })
+
+	j, err := json.Marshal(s.JSONSchema())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{` +
+		`"age":{"type":"integer","exclusiveMinimum":0,"exclusiveMaximum":150},` +
+		`"code":{"type":"string","pattern":"^[a-z]+$"},` +
+		`"color":{"type":"string","enum":["red","green"]},` +
+		`"custom":{"type":"string"},` +
+		`"name":{"type":"string","minLength":2,"maxLength":20},` +
+		`"nick":{"type":"string"},` +
+		`"rate":{"type":"number","exclusiveMaximum":1.5},` +
+		`"tags":{"type":"array","items":{"type":"string","maxLength":10},"maxItems":3,"uniqueItems":true}},` +
+		`"required":["name"]}`
+
+	if string(j) != expected {
+		t.Errorf("expected\n%s\nbut got\n%s", expected, j)
+	}
+}
@@ -0,0 +1,148 @@
+package formspec
+
+import (
+	"net/http"
+	"sort"
+)
+
+// RuleMessageUnknown is used for fields that are not in the spec in strict mode. See Formspec.Strict.
+var RuleMessageUnknown = "is not allowed."
+
+// KeyedForm is a Form that can list keys of submitted fields. It is used in strict mode.
+// *http.Request is treated as KeyedForm by reading r.Form and r.MultipartForm, and url.Values can be used through Values.
+type KeyedForm interface {
+	Form
+	FormKeys() []string
+}
+
+// FormKeys returns keys in sorted order.
+func (v Values) FormKeys() []string {
+	keys := make([]string, 0, len(v))
+
+	for key := range v {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// formKeys returns keys of the fields submitted in the form. ok is false when the form can't list them.
+func formKeys(f Form) (keys []string, ok bool) {
+	switch f := f.(type) {
+	case *subForm:
+		return f.keys()
+	case KeyedForm:
+		return f.FormKeys(), true
+	case *http.Request:
+		// FormValue parses the request body and populates Request.Form and Request.MultipartForm.
+		f.FormValue("")
+
+		seen := map[string]bool{}
+
+		for key := range f.Form {
+			seen[key] = true
+		}
+
+		if f.MultipartForm != nil {
+			for key := range f.MultipartForm.File {
+				seen[key] = true
+			}
+		}
+
+		for key := range seen {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+		return keys, true
+	}
+
+	return nil, false
+}
+
+// Allow allows the fields in strict mode though they have no rules. e.g. CSRF tokens
+//
+//	s.Strict = true
+//	s.Allow("csrf_token", "_method")
+func (f *Formspec) Allow(fields ...string) *Formspec {
+	if f.allowed == nil {
+		f.allowed = map[string]bool{}
+	}
+
+	for _, field := range fields {
+		f.allowed[field] = true
+	}
+
+	return f
+}
+
+// knownFields returns fields that are allowed in strict mode.
+// They are fields that have rules, fields that rules refer to (groups, conditions and cross-field rules) and allowed fields.
+func (f *Formspec) knownFields() map[string]bool {
+	known := map[string]bool{}
+
+	for field := range f.allowed {
+		known[field] = true
+	}
+
+	for _, rule := range f.Rules {
+		// The name of the group is known only when it is one of the fields. e.g. "coupon_code" of MutuallyExclusive("coupon_code", "coupon_code", "gift_card")
+		if group := rule.GroupFields(); group != nil {
+			for _, field := range group {
+				known[field] = true
+			}
+		} else {
+			known[rule.Field] = true
+		}
+
+		for _, c := range rule.Conditions() {
+			known[c.Field] = true
+		}
+
+		if meta := rule.Meta(); meta != nil {
+			if field, ok := meta.Params["field"].(string); ok {
+				known[field] = true
+			}
+		}
+	}
+
+	return known
+}
+
+// known returns true if the key is known by the spec or specs mounted on it. See Formspec.knownFields.
+func (f *Formspec) known(key string) bool {
+	known := f.knownFields()
+
+	if known[key] || known[dotPath(key)] {
+		return true
+	}
+
+	for _, mount := range f.mounts {
+		if mount.known(dotPath(key)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// unknownErrors returns errors for the fields in the form that are not known. See Formspec.known.
+// Forms that can't list keys of their fields are not checked.
+func (f *Formspec) unknownErrors(form Form, m *messenger) []*Error {
+	keys, ok := formKeys(form)
+
+	if !ok {
+		return nil
+	}
+
+	var errs []*Error
+
+	for _, key := range keys {
+		if !f.known(key) {
+			errs = append(errs, m.fieldError(key, NewRuleError("unknown", nil, RuleMessageUnknown)))
+		}
+	}
+
+	return errs
+}
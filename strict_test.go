@@ -0,0 +1,111 @@
+package formspec
+
+import (
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// -----------------------------------------------------------------------------
+// Test formspec.Strict
+// -----------------------------------------------------------------------------
+
+func TestStrict(t *testing.T) {
+	s := New()
+	s.Strict = true
+	s.Allow("csrf_token")
+	s.Rule("name", RuleRequired())
+	s.Rule("password_confirmation", RuleEqualToField("password"))
+	s.RequiredIf("company", IfEquals("type", "business"))
+	s.AtLeastOneOf("contact", "email", "phone")
+
+	f := Values(url.Values{
+		"name":                  {"toqoz"},
+		"password":              {"a"},
+		"password_confirmation": {"a"},
+		"type":                  {"personal"},
+		"email":                 {"a@example.com"},
+		"csrf_token":            {"x"},
+		"contact":               {"x"},
+		"is_admin":              {"1"},
+		"role":                  {"admin"},
+	})
+
+	r := s.Validate(f)
+
+	// The name of the group is not a field.
+	expected := []*Error{
+		{Field: "contact", Message: "contact is not allowed.", Code: "unknown"},
+		{Field: "is_admin", Message: "is_admin is not allowed.", Code: "unknown"},
+		{Field: "role", Message: "role is not allowed.", Code: "unknown"},
+	}
+
+	if r.Ok || !reflect.DeepEqual(r.Errors, expected) {
+		t.Errorf("expected errors %v, but got %v", expected, r.Errors)
+	}
+
+	// Unknown fields are not checked when strict mode is off.
+	s.Strict = false
+
+	if r := s.Validate(f); !r.Ok {
+		t.Errorf("validation error is not expected, but got %v", r.Errors)
+	}
+
+	// Forms that can't list keys are not checked.
+	s.Strict = true
+
+	if r := s.Validate(newDummyform().Set("name", "toqoz").Set("email", "a").Set("is_admin", "1")); !r.Ok {
+		t.Errorf("validation error is not expected, but got %v", r.Errors)
+	}
+}
+
+func TestStrict_Request(t *testing.T) {
+	s := New()
+	s.Strict = true
+	s.Rule("name", RuleRequired())
+	s.FileRule("avatar", RuleFileMaxSize(1024))
+
+	body := &strings.Builder{}
+	w := multipart.NewWriter(body)
+	w.WriteField("name", "toqoz")
+	w.WriteField("is_admin", "1")
+	fw, _ := w.CreateFormFile("avatar", "a.png")
+	fw.Write([]byte("x"))
+	fw, _ = w.CreateFormFile("backdoor", "b.png")
+	fw.Write([]byte("x"))
+	w.Close()
+
+	req, _ := http.NewRequest("POST", "/?debug=1", strings.NewReader(body.String()))
+	req.Header.Set("Content-Type", w.FormDataContentType())
+
+	r := s.ValidateLocale(req, "ja")
+
+	expected := []string{"backdoorは送信できない項目です。", "debugは送信できない項目です。", "is_adminは送信できない項目です。"}
+
+	if len(r.Errors) != len(expected) {
+		t.Fatalf("expected %d errors, but got %v", len(expected), r.Errors)
+	}
+
+	for i, e := range expected {
+		if r.Errors[i].Message != e {
+			t.Errorf("expected error `%s`, but got `%s`", e, r.Errors[i].Message)
+		}
+	}
+}
+
+func TestStrict_Declarative(t *testing.T) {
+	s, err := ParseYAML([]byte("strict: true\nallow: [csrf_token]\nfields:\n  - field: name\n    rules: [required]\n"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := s.Validate(Values(url.Values{"name": {"toqoz"}, "csrf_token": {"x"}, "role": {"admin"}}))
+
+	if len(r.Errors) != 1 || r.Errors[0].Field != "role" {
+		t.Errorf("expected an error for `role`, but got %v", r.Errors)
+	}
+}
@@ -0,0 +1,198 @@
+package formspec
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// NewFromStruct builds *Formspec from `formspec` tags of the struct.
+//
+//	type SignUp struct {
+//		Name string `formspec:"name,required,maxlen=20,filters=trim|collapsespace"`
+//		Age  string `formspec:"age,int,allowblank,message='must be integer. ok?'"`
+//		Tags []string `formspec:"tags,maxitems=5,unique"`
+//	}
+//
+// The first element of the tag is the field name. If it is empty, the name of the struct field is used.
+// Following elements are names of rules in DefaultRegistry (with parameters after `=`) and options, `allowblank`, `bail` (See Formspec.BailField), `on=...` (scenarios separated by `|`. See Rule.On), `label=...`, `filters=...` (See Registry.ParseFilters), `message=...`, `fullmessage=...` and `layout=...` (See ValidateInto).
+// Options are applied to all rules of the field. Values containing `,` must be quoted with `'`, and parameters are separated by `,` in it.
+// e.g. `formspec:"color,in='red,green,blue'"`
+// Fields tagged with `formspec:"-"` and fields without the tag are ignored.
+func NewFromStruct(v interface{}) (*Formspec, error) {
+	t := reflect.TypeOf(v)
+
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("formspec: NewFromStruct requires struct, but got %v", t)
+	}
+
+	f := New()
+
+	if err := f.addStructRules(t); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func (f *Formspec) addStructRules(t reflect.Type) error {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, ok := sf.Tag.Lookup("formspec")
+
+		if !ok {
+			if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+				if err := f.addStructRules(sf.Type); err != nil {
+					return err
+				}
+			}
+
+			continue
+		}
+
+		if tag == "-" || sf.PkgPath != "" {
+			continue
+		}
+
+		if err := f.addTagRules(t.Name()+"."+sf.Name, sf.Name, tag); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (f *Formspec) addTagRules(where, defaultField, tag string) error {
+	elems, err := splitTag(tag)
+
+	if err != nil {
+		return fmt.Errorf("formspec: %s: %v", where, err)
+	}
+
+	field := elems[0]
+
+	if field == "" {
+		field = defaultField
+	}
+
+	var (
+		rules       []*Rule
+		filters     []DescribedFilter
+		allowBlank  bool
+		scenarios   []string
+		message     string
+		fullMessage string
+	)
+
+	for _, elem := range elems[1:] {
+		name, value, hasValue := strings.Cut(elem, "=")
+
+		switch name {
+		case "allowblank":
+			allowBlank = true
+			continue
+		case "bail":
+			f.BailField(field)
+			continue
+		case "on":
+			scenarios = append(scenarios, strings.Split(value, "|")...)
+			continue
+		case "label":
+			f.Label(field, value)
+			continue
+		case "filters":
+			filterFuncs, err := DefaultRegistry.ParseFilters(value)
+
+			if err != nil {
+				return fmt.Errorf("formspec: %s: %v", where, err)
+			}
+
+			filters = append(filters, filterFuncs...)
+			continue
+		case "message":
+			message = value
+			continue
+		case "fullmessage":
+			fullMessage = value
+			continue
+		case "layout":
+			// This is used by ValidateInto.
+			continue
+		}
+
+		var args []string
+
+		if hasValue {
+			args = strings.Split(value, ",")
+		}
+
+		if b, ok := DefaultRegistry.multiRules[name]; ok {
+			ruleFunc, err := b(args)
+
+			if err != nil {
+				return fmt.Errorf("formspec: %s: bad parameter for %s: %v", where, name, err)
+			}
+
+			rules = append(rules, f.MultiRule(field, ruleFunc))
+			continue
+		}
+
+		b, ok := DefaultRegistry.rules[name]
+
+		if !ok {
+			return fmt.Errorf("formspec: %s: unknown rule %q", where, name)
+		}
+
+		ruleFunc, err := b(args)
+
+		if err != nil {
+			return fmt.Errorf("formspec: %s: bad parameter for %s: %v", where, name, err)
+		}
+
+		rules = append(rules, f.Rule(field, ruleFunc))
+	}
+
+	for _, rule := range rules {
+		if allowBlank {
+			rule.AllowBlank()
+		}
+
+		rule.On(scenarios...).Message(message).FullMessage(fullMessage)
+		for _, filter := range filters {
+			rule.Filter(filter)
+		}
+	}
+
+	return nil
+}
+
+// splitTag splits the tag by `,`. Parts quoted with `'` can contain `,`.
+func splitTag(tag string) ([]string, error) {
+	var (
+		elems  []string
+		elem   []byte
+		quoted bool
+	)
+
+	for i := 0; i < len(tag); i++ {
+		switch c := tag[i]; {
+		case c == '\'':
+			quoted = !quoted
+		case c == ',' && !quoted:
+			elems = append(elems, string(elem))
+			elem = elem[:0]
+		default:
+			elem = append(elem, c)
+		}
+	}
+
+	if quoted {
+		return nil, fmt.Errorf("unterminated quote in %q", tag)
+	}
+
+	return append(elems, string(elem)), nil
+}
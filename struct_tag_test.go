@@ -0,0 +1,92 @@
+package formspec
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+type structTagTestSignUp struct {
+	Name     string   `formspec:"name,required,maxlen=5,filters=trim"`
+	Age      string   `formspec:"age,int,allowblank,message='must be integer, ok?'"`
+	Nick     string   `formspec:",required,fullmessage=Please enter your cool nick."`
+	Tags     []string `formspec:"tags,maxitems=2,unique"`
+	Color    string   `formspec:"color,in='red,green',allowblank,label=Favorite color"`
+	Ignored  string   `formspec:"-"`
+	NoTag    string
+	internal string `formspec:"internal,required"`
+}
+
+func TestNewFromStruct(t *testing.T) {
+	s, err := NewFromStruct(&structTagTestSignUp{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Test
+	//   when all values are valid
+	//     formspec should not return error
+	f := Values(url.Values{"name": {" toqoz "}, "Nick": {"toqoz"}, "tags": {"a", "b"}})
+
+	if r := s.Validate(f); !r.Ok {
+		t.Errorf("validation error is not expected, but got %v", r.Errors)
+	}
+
+	// Test
+	//   when all values are invalid
+	//     formspec should return errors with messages given by the tag
+	f = Values(url.Values{"name": {"toqoz403"}, "age": {"x"}, "tags": {"a", "a", "b"}, "color": {"blue"}})
+	r := s.Validate(f)
+
+	expected := []string{
+		"name is too long. Max is 5 character.",
+		"age must be integer, ok?",
+		"Please enter your cool nick.",
+		"tags must have at most 2 items.",
+		"tags must not have duplicate items. Item 1 is a duplicate.",
+		"Favorite color is not included in the list.",
+	}
+
+	if len(r.Errors) != len(expected) {
+		t.Fatalf("expected %d errors, but got %v", len(expected), r.Errors)
+	}
+
+	for i, e := range expected {
+		if r.Errors[i].Message != e {
+			t.Errorf("expected error `%s`, but got `%s`", e, r.Errors[i].Message)
+		}
+	}
+}
+
+func TestNewFromStruct_Errors(t *testing.T) {
+	examples := []struct {
+		v        interface{}
+		expected string
+	}{
+		{"string", "requires struct"},
+		{&struct {
+			Name string `formspec:"name,unknown"`
+		}{}, `unknown rule "unknown"`},
+		{&struct {
+			Name string `formspec:"name,maxlen=x"`
+		}{}, "bad parameter for maxlen"},
+		{&struct {
+			Name string `formspec:"name,required=1"`
+		}{}, "bad parameter for required"},
+		{&struct {
+			Name string `formspec:"name,format=("`
+		}{}, "bad parameter for format"},
+		{&struct {
+			Name string `formspec:"name,message='unterminated"`
+		}{}, "unterminated quote"},
+	}
+
+	for _, example := range examples {
+		_, err := NewFromStruct(example.v)
+
+		if err == nil || !strings.Contains(err.Error(), example.expected) {
+			t.Errorf("expected error containing `%s`, but got %v", example.expected, err)
+		}
+	}
+}